@@ -1,9 +1,7 @@
 package bnnapi
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -19,25 +17,49 @@ import (
 var json = jsoniter.ConfigCompatibleWithStandardLibrary
 
 type Client struct {
-	key, secret string
-	subaccount  string
-	client      *http.Client
-	window      int
+	key        string
+	signer     Signer
+	subaccount string
+	client     *http.Client
+	window     int
+	endpoints  Endpoints
+	limiter    *RateLimiter
+	ctx        context.Context
+	clock      *clockBranch
+	retry      *RetryPolicy
+	validation OrderValidation
+	filters    *filterCache
+	registry   *SymbolRegistryBranch
+	dryRun     dryRunConfig
 	// addition
 	spotUser *spotUserDataBranch
 	perpUser *perpUserDataBranch
+	// cross margin under "", isolated margin under the symbol
+	marginUsers *marginUsersBranch
 }
 
+// hmac key, use NewWithSigner for ed25519 or rsa keys
 func New(key, secret, subaccount string) *Client {
+	return NewWithSigner(key, NewHMACSigner(secret), subaccount)
+}
+
+func NewWithSigner(key string, signer Signer, subaccount string) *Client {
 	hc := &http.Client{
 		Timeout: 10 * time.Second,
 	}
 	return &Client{
 		key:        key,
-		secret:     secret,
+		signer:     signer,
 		subaccount: subaccount,
 		client:     hc,
 		window:     5000,
+		endpoints:  ProductionEndpoints,
+		limiter:    defaultRateLimiter,
+		clock:      newClockBranch(),
+		filters:    newFilterCache(),
+		marginUsers: &marginUsersBranch{
+			users: make(map[string]*marginUserDataBranch),
+		},
 	}
 }
 
@@ -46,36 +68,88 @@ func (c *Client) SetRecvWindow(recvWindow int) {
 	c.window = recvWindow
 }
 
+// WithContext returns a shallow copy of the client whose every rest call is bound to ctx,
+// e.g. client.WithContext(ctx).SpotPlaceOrder(...) for a per call deadline or cancellation.
+// The copy shares the http client, rate limiter and private channels with the original,
+// setters called on the copy only change the copy.
+func (c *Client) WithContext(ctx context.Context) *Client {
+	if ctx == nil {
+		panic("nil context")
+	}
+	c2 := *c
+	c2.ctx = ctx
+	return &c2
+}
+
+// the context bound by WithContext, background if none
+func (c *Client) Context() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.Background()
+}
+
 func (c *Client) do(product, method, path string, data interface{}, sign bool, stream bool) (response []byte, err error) {
-	var ENDPOINT string
-	switch product {
-	case "spot":
-		ENDPOINT = "https://api.binance.com"
-	case "future":
-		ENDPOINT = "https://fapi.binance.com"
-	case "special":
-		ENDPOINT = "https://www.binance.com"
+	var resynced bool
+	for attempt := 0; ; attempt++ {
+		response, err = c.doOnce(product, method, path, data, sign, stream)
+		if err == nil {
+			return response, nil
+		}
+		if sign && !resynced && IsErrorCode(err, ErrCodeInvalidTimestamp) {
+			// the request is rejected before matching, resync the clock and try once more
+			resynced = true
+			if errSync := c.SyncTime(product); errSync != nil {
+				return nil, err
+			}
+			response, err = c.doOnce(product, method, path, data, sign, stream)
+			if err == nil {
+				return response, nil
+			}
+		}
+		wait, ok := c.retryDelay(method, attempt, err)
+		if !ok {
+			return nil, err
+		}
+		if errSleep := c.sleep(wait); errSleep != nil {
+			return nil, err
+		}
 	}
+}
+
+func (c *Client) doOnce(product, method, path string, data interface{}, sign bool, stream bool) (response []byte, err error) {
+	ENDPOINT := c.endpoints.rest(product)
 	values, err := query.Values(data)
 	if err != nil {
 		return nil, err
 	}
 	payload := values.Encode()
+	if c.limiter != nil {
+		if weight, orders := requestCost(product, method, path, values); weight != 0 || orders != 0 {
+			if err := c.limiter.acquire(c.Context(), product, c.key, weight, orders); err != nil {
+				return nil, err
+			}
+		}
+	}
 	if sign {
-		payload = fmt.Sprintf("%s&timestamp=%v&recvWindow=%d", payload, time.Now().UnixNano()/(1000*1000), c.window)
-		mac := hmac.New(sha256.New, []byte(c.secret))
-		_, err = mac.Write([]byte(payload))
+		payload = fmt.Sprintf("%s&timestamp=%v&recvWindow=%d", payload, c.serverTimestamp(product), c.window)
+		sig, err := c.sign(payload)
 		if err != nil {
 			return nil, err
 		}
-		payload = fmt.Sprintf("%s&signature=%s", payload, hex.EncodeToString(mac.Sum(nil)))
+		payload = fmt.Sprintf("%s&signature=%s", payload, sig)
 	}
 	var req *http.Request
 	if method == http.MethodGet {
-		req, err = http.NewRequest(method, fmt.Sprintf("%s/%s?%s", ENDPOINT, path, payload), nil)
+		req, err = http.NewRequestWithContext(c.Context(), method, fmt.Sprintf("%s/%s?%s", ENDPOINT, path, payload), nil)
 	} else {
-		req, err = http.NewRequest(method, fmt.Sprintf("%s/%s", ENDPOINT, path), strings.NewReader(payload))
-		req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		req, err = http.NewRequestWithContext(c.Context(), method, fmt.Sprintf("%s/%s", ENDPOINT, path), strings.NewReader(payload))
+		if err == nil {
+			req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+		}
+	}
+	if err != nil {
+		return nil, err
 	}
 	if sign || stream {
 		req.Header.Add("X-MBX-APIKEY", c.key)
@@ -90,8 +164,15 @@ func (c *Client) do(product, method, path string, data interface{}, sign bool, s
 	if err != nil {
 		return nil, err
 	}
+	var apiErr *APIError
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status %d: %v", resp.StatusCode, string(response))
+		apiErr = newAPIError(resp, response, method, path)
+	}
+	if c.limiter != nil {
+		c.limiter.update(product, c.key, resp, apiErr)
+	}
+	if apiErr != nil {
+		return nil, apiErr
 	}
 	return response, err
 }
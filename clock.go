@@ -0,0 +1,127 @@
+package bnnapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type clockBranch struct {
+	sync.RWMutex
+	offset map[string]time.Duration
+	rtt    map[string]time.Duration
+	synced map[string]time.Time
+	cancel *context.CancelFunc
+}
+
+// measure the offset between the server clock and the local one, "spot" or "future"
+func (c *Client) SyncTime(product string) error {
+	var path string
+	switch product {
+	case "spot":
+		path = "api/v3/time"
+	case "future":
+		path = "fapi/v1/time"
+	default:
+		return errors.New("unsupported product")
+	}
+	start := time.Now()
+	res, err := c.do(product, http.MethodGet, path, nil, false, false)
+	if err != nil {
+		return err
+	}
+	end := time.Now()
+	serverTime := &ServerTime{}
+	err = json.Unmarshal(res, serverTime)
+	if err != nil {
+		return err
+	}
+	rtt := end.Sub(start)
+	// assume the server stamped the time in the middle of the round trip
+	offset := time.UnixMilli(serverTime.ServerTime).Sub(start.Add(rtt / 2))
+	c.clock.Lock()
+	defer c.clock.Unlock()
+	c.clock.offset[product] = offset
+	c.clock.rtt[product] = rtt
+	c.clock.synced[product] = end
+	return nil
+}
+
+// keep the offsets of spot and future fresh, the first sync is done before returning
+func (c *Client) StartTimeSync(interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("sync interval must be positive")
+	}
+	if err := c.SyncTime("spot"); err != nil {
+		return err
+	}
+	if err := c.SyncTime("future"); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.clock.Lock()
+	if c.clock.cancel != nil {
+		(*c.clock.cancel)()
+	}
+	c.clock.cancel = &cancel
+	c.clock.Unlock()
+	client := c.WithContext(ctx)
+	go func() {
+		refresh := time.NewTicker(interval)
+		defer refresh.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-refresh.C:
+				// keep the last offset when failing
+				client.SyncTime("spot")
+				client.SyncTime("future")
+			}
+		}
+	}()
+	return nil
+}
+
+func (c *Client) StopTimeSync() {
+	c.clock.Lock()
+	defer c.clock.Unlock()
+	if c.clock.cancel != nil {
+		(*c.clock.cancel)()
+		c.clock.cancel = nil
+	}
+}
+
+// server time minus local time and the round trip time of the last sync
+func (c *Client) TimeOffset(product string) (offset, rtt time.Duration) {
+	c.clock.RLock()
+	defer c.clock.RUnlock()
+	return c.clock.offset[product], c.clock.rtt[product]
+}
+
+// the last time the offset of the product got measured, zero if never
+func (c *Client) LastTimeSync(product string) time.Time {
+	c.clock.RLock()
+	defer c.clock.RUnlock()
+	return c.clock.synced[product]
+}
+
+// internal funcs ------------------------------------------------
+
+func newClockBranch() *clockBranch {
+	return &clockBranch{
+		offset: make(map[string]time.Duration),
+		rtt:    make(map[string]time.Duration),
+		synced: make(map[string]time.Time),
+	}
+}
+
+// local time corrected by the measured offset, in milliseconds
+func (c *Client) serverTimestamp(product string) int64 {
+	c.clock.RLock()
+	offset := c.clock.offset[product]
+	c.clock.RUnlock()
+	return time.Now().Add(offset).UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,225 @@
+package bnnapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type CountdownCancelAllOpts struct {
+	Symbol string `url:"symbol"`
+	// in milliseconds, 0 turns the countdown off
+	CountdownTime int64 `url:"countdownTime"`
+}
+
+type CountdownCancelAllResponse struct {
+	Symbol        string `json:"symbol"`
+	CountdownTime string `json:"countdownTime"`
+}
+
+// every open order of the symbol is canceled when the countdown isn't refreshed in time, 0 turns it off
+func (b *Client) PerpCountdownCancelAll(symbol string, countdown time.Duration) (*CountdownCancelAllResponse, error) {
+	opts := CountdownCancelAllOpts{
+		Symbol:        strings.ToUpper(symbol),
+		CountdownTime: countdown.Milliseconds(),
+	}
+	res, err := b.do("future", http.MethodPost, "fapi/v1/countdownCancelAll", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := &CountdownCancelAllResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// keeps the countdown of the perp symbols running, the orders are canceled by the exchange once the process stops refreshing it
+type PerpHeartbeatBranch struct {
+	client    *Client
+	logger    *log.Logger
+	symbols   []string
+	countdown time.Duration
+	cancel    *context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// refresh the countdown of the symbols every interval, interval has to be shorter than countdown.
+// The first refresh is done before returning.
+func NewPerpHeartbeat(client *Client, symbols []string, countdown, interval time.Duration, logger *log.Logger) (*PerpHeartbeatBranch, error) {
+	if len(symbols) == 0 {
+		return nil, errors.New("no symbol for the heartbeat")
+	}
+	if interval <= 0 || interval >= countdown {
+		return nil, errors.New("heartbeat interval has to be positive and shorter than the countdown")
+	}
+	h := &PerpHeartbeatBranch{
+		client:    client,
+		logger:    logger,
+		countdown: countdown,
+		done:      make(chan struct{}),
+	}
+	for _, symbol := range symbols {
+		h.symbols = append(h.symbols, strings.ToUpper(symbol))
+	}
+	if err := h.beat(client, countdown); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = &cancel
+	go h.maintain(ctx, interval)
+	return h, nil
+}
+
+// stop refreshing and turn the countdown off, so the orders stay
+func (h *PerpHeartbeatBranch) Close() error {
+	var err error
+	h.closeOnce.Do(func() {
+		(*h.cancel)()
+		<-h.done
+		err = h.beat(h.client, 0)
+	})
+	return err
+}
+
+// spot has no countdown, the watchdog cancels the orders of the symbols itself
+// when the spot user data stream has been silent for longer than the threshold
+type SpotWatchdogBranch struct {
+	client    *Client
+	logger    *log.Logger
+	symbols   []string
+	threshold time.Duration
+	cancel    *context.CancelFunc
+	done      chan struct{}
+	closeOnce sync.Once
+
+	mux sync.Mutex
+	// set once the orders are canceled, until the stream is back
+	fired bool
+}
+
+// InitSpotPrivateChannel has to be called first, Binance pings the stream every few minutes
+// so the threshold should be well above that.
+func NewSpotWatchdog(client *Client, symbols []string, threshold time.Duration, logger *log.Logger) (*SpotWatchdogBranch, error) {
+	if client.spotUser == nil {
+		return nil, errors.New("spot user data stream is not started")
+	}
+	if len(symbols) == 0 {
+		return nil, errors.New("no symbol for the watchdog")
+	}
+	if threshold <= 0 {
+		return nil, errors.New("watchdog threshold has to be positive")
+	}
+	w := &SpotWatchdogBranch{
+		client:    client,
+		logger:    logger,
+		threshold: threshold,
+		done:      make(chan struct{}),
+	}
+	for _, symbol := range symbols {
+		w.symbols = append(w.symbols, strings.ToUpper(symbol))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = &cancel
+	go w.maintain(ctx)
+	return w, nil
+}
+
+func (w *SpotWatchdogBranch) Close() {
+	w.closeOnce.Do(func() {
+		(*w.cancel)()
+		<-w.done
+	})
+}
+
+// true after the watchdog canceled the orders, until the stream is alive again
+func (w *SpotWatchdogBranch) Fired() bool {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	return w.fired
+}
+
+// internal funcs ------------------------------------------------
+
+func (h *PerpHeartbeatBranch) maintain(ctx context.Context, interval time.Duration) {
+	defer close(h.done)
+	beat := time.NewTicker(interval)
+	defer beat.Stop()
+	client := h.client.WithContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-beat.C:
+			// the errors of every symbol are joined into one, ask ctx if it was closed
+			if err := h.beat(client, h.countdown); err != nil && ctx.Err() == nil {
+				// the countdown is still running, try again on the next beat
+				h.logger.Warningf("Refreshing perp countdown with err: %s\n", err.Error())
+			}
+		}
+	}
+}
+
+func (h *PerpHeartbeatBranch) beat(client *Client, countdown time.Duration) error {
+	var errs []string
+	for _, symbol := range h.symbols {
+		if _, err := client.PerpCountdownCancelAll(symbol, countdown); err != nil {
+			errs = append(errs, symbol+": "+err.Error())
+		}
+	}
+	if len(errs) != 0 {
+		return errors.New(strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func (w *SpotWatchdogBranch) maintain(ctx context.Context) {
+	defer close(w.done)
+	// check a few times within the threshold
+	period := w.threshold / 4
+	if period < time.Second {
+		period = time.Second
+	}
+	check := time.NewTicker(period)
+	defer check.Stop()
+	client := w.client.WithContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-check.C:
+			w.check(client)
+		}
+	}
+}
+
+func (w *SpotWatchdogBranch) check(client *Client) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	silent := w.client.spotUser.link.since()
+	if silent <= w.threshold {
+		w.fired = false
+		return
+	}
+	if w.fired {
+		return
+	}
+	w.logger.Warningf("Spot user data stream silent for %s, cancel all orders of %s\n", silent.Truncate(time.Second), strings.Join(w.symbols, ", "))
+	fired := true
+	for _, symbol := range w.symbols {
+		// -2011 when there is no open order
+		if _, err := client.CancelAllSpotOrders(symbol); err != nil && !IsErrorCode(err, ErrCodeCancelRejected) {
+			// keep trying on the next check
+			w.logger.Warningf("Watchdog canceling %s orders with err: %s\n", symbol, err.Error())
+			fired = false
+		}
+	}
+	w.fired = fired
+}
@@ -0,0 +1,243 @@
+package bnnapi
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var ErrDryRunUnsupported = errors.New("not supported in dry run")
+
+type CommissionRates struct {
+	Maker string `json:"maker"`
+	Taker string `json:"taker"`
+}
+
+// response of api/v3/order/test, empty unless the commission rates are computed
+type SpotTestOrderResponse struct {
+	StandardCommissionForOrder CommissionRates `json:"standardCommissionForOrder"`
+	SpecialCommissionForOrder  CommissionRates `json:"specialCommissionForOrder"`
+	TaxCommissionForOrder      CommissionRates `json:"taxCommissionForOrder"`
+	Discount                   struct {
+		EnabledForAccount bool   `json:"enabledForAccount"`
+		EnabledForSymbol  bool   `json:"enabledForSymbol"`
+		DiscountAsset     string `json:"discountAsset"`
+		Discount          string `json:"discount"`
+	} `json:"discount"`
+}
+
+type dryRunConfig struct {
+	enabled    bool
+	commission bool
+}
+
+// In dry run spot and margin orders go to api/v3/order/test and perp orders are answered locally,
+// the returned order is synthesized with a negative order id.
+// computeCommissionRates asks the test endpoint for the commission rates of every order, found in the DryRun field of the spot response.
+// Order lists, cancelReplace and amend return ErrDryRunUnsupported.
+func (c *Client) SetDryRun(enabled, computeCommissionRates bool) {
+	c.dryRun = dryRunConfig{
+		enabled:    enabled,
+		commission: computeCommissionRates,
+	}
+}
+
+func (c *Client) IsDryRun() bool {
+	return c.dryRun.enabled
+}
+
+// validate the order on the exchange without sending it to the matching engine
+func (b *Client) SpotTestOrder(req SpotOrderRequest, computeCommissionRates bool) (*SpotTestOrderResponse, error) {
+	req.normalize()
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return b.spotTestOrder(req, computeCommissionRates)
+}
+
+// internal funcs ------------------------------------------------
+
+type spotTestOrderOpts struct {
+	SpotOrderRequest
+	ComputeCommissionRates bool `url:"computeCommissionRates,omitempty"`
+}
+
+// fake ids count down from -1 so they never collide with the real ones
+var dryRunOrderID int64
+
+func nextDryRunOrderID() int64 {
+	return atomic.AddInt64(&dryRunOrderID, -1)
+}
+
+func (b *Client) spotTestOrder(req SpotOrderRequest, computeCommissionRates bool) (*SpotTestOrderResponse, error) {
+	opts := spotTestOrderOpts{
+		SpotOrderRequest:       req,
+		ComputeCommissionRates: computeCommissionRates,
+	}
+	res, err := b.do("spot", http.MethodPost, "api/v3/order/test", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := &SpotTestOrderResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (b *Client) dryRunSpotOrder(req SpotOrderRequest) (*SpotOrderResponse, error) {
+	test, err := b.spotTestOrder(req, b.dryRun.commission)
+	if err != nil {
+		return nil, err
+	}
+	if req.ClientID == "" {
+		req.ClientID = NewClientOrderID()
+	}
+	resp := &SpotOrderResponse{
+		Symbol:              req.Symbol,
+		OrderID:             int(nextDryRunOrderID()),
+		OrderListID:         -1,
+		ClientOrderID:       req.ClientID,
+		TransactTime:        time.Now().UnixMilli(),
+		Price:               orZero(req.Price),
+		OrigQty:             orZero(req.Qty),
+		ExecutedQty:         "0",
+		CummulativeQuoteQty: "0",
+		Status:              "NEW",
+		TimeInForce:         req.TimeInForce,
+		Type:                req.Type,
+		Side:                req.Side,
+		StopPrice:           req.StopPrice,
+		IcebergQty:          req.IcebergQty,
+		TrailingDelta:       req.TrailingDelta,
+		StrategyID:          req.StrategyID,
+		StrategyType:        req.StrategyType,
+		WorkingTime:         time.Now().UnixMilli(),
+		SelfTradePrevention: req.SelfTradePreventionMode,
+	}
+	if b.dryRun.commission {
+		resp.DryRun = test
+	}
+	return resp, nil
+}
+
+func (b *Client) dryRunMarginOrder(opts PlaceOrderOptsIsomargin) (*MarginOrderResponse, error) {
+	// the test endpoint takes the spot parameters only
+	req := SpotOrderRequest{
+		Symbol: opts.Symbol,
+		Side:   opts.Side,
+		Type:   opts.Type,
+		Price:  opts.Price,
+		Qty:    opts.Qty,
+	}
+	switch req.Type {
+	case "LIMIT", "STOP_LOSS_LIMIT", "TAKE_PROFIT_LIMIT":
+		// margin orders always carry one
+		req.TimeInForce = opts.TimeInForce
+	}
+	spot, err := b.dryRunSpotOrder(req)
+	if err != nil {
+		return nil, err
+	}
+	return &MarginOrderResponse{
+		Symbol:              spot.Symbol,
+		OrderID:             spot.OrderID,
+		ClientOrderID:       spot.ClientOrderID,
+		TransactTime:        spot.TransactTime,
+		Price:               spot.Price,
+		OrigQty:             spot.OrigQty,
+		ExecutedQty:         spot.ExecutedQty,
+		CummulativeQuoteQty: spot.CummulativeQuoteQty,
+		Status:              spot.Status,
+		TimeInForce:         spot.TimeInForce,
+		Type:                spot.Type,
+		Side:                spot.Side,
+		IsIsolated:          strings.ToUpper(opts.Isolated) == "TRUE",
+	}, nil
+}
+
+// nothing is sent, the filters are still checked when the order validation is on
+func dryRunPerpOrder(req PerpOrderRequest) *PerpOrderResponse {
+	if req.ClientID == "" {
+		req.ClientID = NewClientOrderID()
+	}
+	positionSide := req.PositionSide
+	if positionSide == "" {
+		positionSide = "BOTH"
+	}
+	workingType := req.WorkingType
+	if workingType == "" {
+		workingType = "CONTRACT_PRICE"
+	}
+	return &PerpOrderResponse{
+		ClientOrderID:           req.ClientID,
+		CumQty:                  "0",
+		CumQuote:                "0",
+		ExecutedQty:             "0",
+		OrderID:                 int(nextDryRunOrderID()),
+		AvgPrice:                "0",
+		OrigQty:                 orZero(req.Qty),
+		Price:                   orZero(req.Price),
+		ReduceOnly:              req.ReduceOnly,
+		Side:                    req.Side,
+		PositionSide:            positionSide,
+		Status:                  "NEW",
+		StopPrice:               orZero(req.StopPrice),
+		ClosePosition:           req.ClosePosition,
+		Symbol:                  req.Symbol,
+		TimeInForce:             req.TimeInForce,
+		Type:                    req.Type,
+		OrigType:                req.Type,
+		ActivatePrice:           req.ActivationPrice,
+		PriceRate:               req.CallbackRate,
+		UpdateTime:              time.Now().UnixMilli(),
+		WorkingType:             workingType,
+		PriceProtect:            req.PriceProtect,
+		PriceMatch:              req.PriceMatch,
+		SelfTradePreventionMode: req.SelfTradePreventionMode,
+		GoodTillDate:            req.GoodTillDate,
+	}
+}
+
+func dryRunPerpBatch(reqs []PerpOrderRequest) PerpBatchOrdersResponse {
+	resp := make(PerpBatchOrdersResponse, len(reqs))
+	for i, req := range reqs {
+		order := dryRunPerpOrder(req)
+		resp[i].Clientorderid = order.ClientOrderID
+		resp[i].Cumqty = order.CumQty
+		resp[i].Cumquote = order.CumQuote
+		resp[i].Executedqty = order.ExecutedQty
+		resp[i].Orderid = order.OrderID
+		resp[i].Avgprice = order.AvgPrice
+		resp[i].Origqty = order.OrigQty
+		resp[i].Price = order.Price
+		resp[i].Reduceonly = order.ReduceOnly
+		resp[i].Side = order.Side
+		resp[i].Positionside = order.PositionSide
+		resp[i].Status = order.Status
+		resp[i].Stopprice = order.StopPrice
+		resp[i].Symbol = order.Symbol
+		resp[i].Timeinforce = order.TimeInForce
+		resp[i].Type = order.Type
+		resp[i].Origtype = order.OrigType
+		resp[i].Activateprice = order.ActivatePrice
+		resp[i].Pricerate = order.PriceRate
+		resp[i].Updatetime = order.UpdateTime
+		resp[i].Workingtype = order.WorkingType
+		resp[i].Priceprotect = order.PriceProtect
+		resp[i].Closeposition = order.ClosePosition
+		resp[i].Pricematch = order.PriceMatch
+		resp[i].Goodtilldate = order.GoodTillDate
+	}
+	return resp
+}
+
+func orZero(value string) string {
+	if value == "" {
+		return "0"
+	}
+	return value
+}
@@ -0,0 +1,125 @@
+package bnnapi
+
+import "strings"
+
+// Endpoints holds every base url the package talks to, without trailing slash.
+// Empty fields fall back to the production value.
+type Endpoints struct {
+	Spot         string // rest, "spot" product
+	Future       string // rest, "future" product
+	Special      string // rest, "special" product (www gateway)
+	SpotStream   string // websocket, spot and margin streams
+	FutureStream string // websocket, perp streams
+	// websocket, perp book ticker stream, FutureStream when empty and FutureStream is set
+	FutureTickerStream string
+}
+
+var ProductionEndpoints = Endpoints{
+	Spot:         "https://api.binance.com",
+	Future:       "https://fapi.binance.com",
+	Special:      "https://www.binance.com",
+	SpotStream:   "wss://stream.binance.com:9443",
+	FutureStream: "wss://fstream.binance.com",
+	// the ticker has always been on fstream3
+	FutureTickerStream: "wss://fstream3.binance.com",
+}
+
+var TestnetEndpoints = Endpoints{
+	Spot:         "https://testnet.binance.vision",
+	Future:       "https://testnet.binancefuture.com",
+	Special:      "https://www.binance.com",
+	SpotStream:   "wss://testnet.binance.vision",
+	FutureStream: "wss://stream.binancefuture.com",
+}
+
+// set all base urls, e.g. TestnetEndpoints or a local mock server
+func (c *Client) SetEndpoints(e Endpoints) {
+	c.endpoints = e.withDefaults()
+}
+
+func (c *Client) Endpoints() Endpoints {
+	return c.endpoints
+}
+
+type StreamOption func(*streamConfig)
+
+type streamConfig struct {
+	endpoints Endpoints
+}
+
+// point a stream constructor to other websocket hosts, the rest hosts are used for snapshots
+func WithEndpoints(e Endpoints) StreamOption {
+	return func(s *streamConfig) {
+		s.endpoints = e.withDefaults()
+	}
+}
+
+func newStreamConfig(opts []StreamOption) streamConfig {
+	s := streamConfig{
+		endpoints: ProductionEndpoints,
+	}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s
+}
+
+func (e Endpoints) withDefaults() Endpoints {
+	if e.Spot == "" {
+		e.Spot = ProductionEndpoints.Spot
+	}
+	if e.Future == "" {
+		e.Future = ProductionEndpoints.Future
+	}
+	if e.Special == "" {
+		e.Special = ProductionEndpoints.Special
+	}
+	if e.SpotStream == "" {
+		e.SpotStream = ProductionEndpoints.SpotStream
+	}
+	if e.FutureTickerStream == "" {
+		e.FutureTickerStream = e.FutureStream
+		if e.FutureStream == "" {
+			e.FutureTickerStream = ProductionEndpoints.FutureTickerStream
+		}
+	}
+	if e.FutureStream == "" {
+		e.FutureStream = ProductionEndpoints.FutureStream
+	}
+	e.Spot = strings.TrimSuffix(e.Spot, "/")
+	e.Future = strings.TrimSuffix(e.Future, "/")
+	e.Special = strings.TrimSuffix(e.Special, "/")
+	e.SpotStream = strings.TrimSuffix(e.SpotStream, "/")
+	e.FutureStream = strings.TrimSuffix(e.FutureStream, "/")
+	e.FutureTickerStream = strings.TrimSuffix(e.FutureTickerStream, "/")
+	return e
+}
+
+func (e Endpoints) rest(product string) string {
+	switch product {
+	case "spot":
+		return e.Spot
+	case "future":
+		return e.Future
+	case "special":
+		return e.Special
+	}
+	return ""
+}
+
+// the perp book ticker has a host of its own
+func (e Endpoints) tickerStream(product string) string {
+	if product == "perp" {
+		return e.FutureTickerStream + "/ws/"
+	}
+	return e.stream(product)
+}
+
+// spot, margin, isomargin and perp
+func (e Endpoints) stream(product string) string {
+	switch product {
+	case "perp", "future":
+		return e.FutureStream + "/ws/"
+	}
+	return e.SpotStream + "/ws/"
+}
@@ -0,0 +1,180 @@
+package bnnapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type ErrorClass int
+
+const (
+	// the request was rejected by the exchange and resending it won't help
+	ErrClassRejected ErrorClass = iota
+	// safe to send again, e.g. -1021 timestamp or a 5xx on a query
+	ErrClassRetryable
+	// 429 or 418, back off for RetryAfter before the next request
+	ErrClassRateLimited
+	// 5xx on order placement, the order may or may not exist, reconcile before resending
+	ErrClassUnknownExecution
+)
+
+func (e ErrorClass) String() string {
+	switch e {
+	case ErrClassRetryable:
+		return "retryable"
+	case ErrClassRateLimited:
+		return "rate limited"
+	case ErrClassUnknownExecution:
+		return "unknown execution status"
+	}
+	return "rejected"
+}
+
+// some of the common codes, see https://binance-docs.github.io/apidocs/spot/en/#error-codes
+const (
+	ErrCodeUnknown             = -1000
+	ErrCodeDisconnected        = -1001
+	ErrCodeTooManyRequests     = -1003
+	ErrCodeUnexpectedResponse  = -1006
+	ErrCodeTimeout             = -1007
+	ErrCodeServerBusy          = -1008
+	ErrCodeFilterFailure       = -1013
+	ErrCodeTooManyOrders       = -1015
+	ErrCodeInvalidTimestamp    = -1021
+	ErrCodeInvalidSignature    = -1022
+	ErrCodeNewOrderRejected    = -2010
+	ErrCodeCancelRejected      = -2011
+	ErrCodeNoSuchOrder         = -2013
+	ErrCodeInvalidAPIKey       = -2014
+	ErrCodeRejectedMBXKey      = -2015
+	ErrCodeBalanceInsufficient = -2019
+	// cancelReplace with one of the two legs failed
+	ErrCodeCancelReplacePartial = -2021
+	// cancelReplace with both legs failed
+	ErrCodeCancelReplaceFailed = -2022
+	// the position mode is already the requested one
+	ErrCodeNoNeedToChangePositionSide = -4059
+	// the symbol already has the requested margin type
+	ErrCodeNoNeedToChangeMarginType = -4046
+)
+
+// APIError is returned by every rest call which got a non 200 status
+type APIError struct {
+	Code       int    `json:"code"`
+	Message    string `json:"msg"`
+	StatusCode int    `json:"-"`
+	// parsed from the Retry-After header, zero if not sent
+	RetryAfter time.Duration `json:"-"`
+	Method     string        `json:"-"`
+	Path       string        `json:"-"`
+	// raw response body, some endpoints put extra data next to code and msg
+	Body []byte `json:"-"`
+}
+
+func (e *APIError) Error() string {
+	if e.Code == 0 && e.Message == "" {
+		return fmt.Sprintf("status %d: %s", e.StatusCode, string(e.Body))
+	}
+	return fmt.Sprintf("status %d: code %d: %s", e.StatusCode, e.Code, e.Message)
+}
+
+func (e *APIError) Class() ErrorClass {
+	switch {
+	case e.StatusCode == http.StatusTooManyRequests || e.StatusCode == http.StatusTeapot:
+		return ErrClassRateLimited
+	case e.Code == ErrCodeTooManyRequests || e.Code == ErrCodeTooManyOrders:
+		return ErrClassRateLimited
+	case e.Code == ErrCodeTimeout:
+		// timeout waiting for response from backend server
+		if isOrderPlacement(e.Method, e.Path) {
+			return ErrClassUnknownExecution
+		}
+		return ErrClassRetryable
+	case e.StatusCode >= http.StatusInternalServerError:
+		if isOrderPlacement(e.Method, e.Path) {
+			return ErrClassUnknownExecution
+		}
+		return ErrClassRetryable
+	case e.Code == ErrCodeInvalidTimestamp || e.Code == ErrCodeDisconnected || e.Code == ErrCodeServerBusy:
+		return ErrClassRetryable
+	}
+	return ErrClassRejected
+}
+
+func (e *APIError) IsRetryable() bool {
+	return e.Class() == ErrClassRetryable
+}
+
+func (e *APIError) IsRateLimited() bool {
+	return e.Class() == ErrClassRateLimited
+}
+
+func (e *APIError) IsRejected() bool {
+	return e.Class() == ErrClassRejected
+}
+
+func (e *APIError) IsUnknownExecution() bool {
+	return e.Class() == ErrClassUnknownExecution
+}
+
+// return the *APIError inside err if there is one
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// check the Binance error code of err, false if err is not an *APIError
+func IsErrorCode(err error, code int) bool {
+	apiErr, ok := AsAPIError(err)
+	if !ok {
+		return false
+	}
+	return apiErr.Code == code
+}
+
+// internal funcs ------------------------------------------------
+
+func newAPIError(resp *http.Response, body []byte, method, path string) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Method:     method,
+		Path:       strings.TrimPrefix(path, "/"),
+		Body:       body,
+	}
+	// not every error has a json body, e.g. 403 from the waf
+	_ = json.Unmarshal(body, apiErr)
+	if after := resp.Header.Get("Retry-After"); after != "" {
+		if sec, err := strconv.Atoi(after); err == nil {
+			apiErr.RetryAfter = time.Duration(sec) * time.Second
+		} else if t, err := http.ParseTime(after); err == nil {
+			apiErr.RetryAfter = time.Until(t)
+		}
+	}
+	return apiErr
+}
+
+// the calls which could leave an order behind when the response is lost
+func isOrderPlacement(method, path string) bool {
+	if method != http.MethodPost && method != http.MethodPut {
+		return false
+	}
+	path = strings.TrimPrefix(path, "/")
+	switch {
+	case path == "api/v3/order", strings.HasPrefix(path, "api/v3/order/"):
+		return !strings.HasSuffix(path, "/test")
+	case strings.HasPrefix(path, "api/v3/orderList/"):
+		return true
+	case path == "sapi/v1/margin/order", strings.HasPrefix(path, "sapi/v1/margin/order/"):
+		return true
+	case path == "fapi/v1/order", path == "fapi/v1/batchOrders":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,575 @@
+package bnnapi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// SymbolFilters is the typed "filters" array of the exchange info, nil if the symbol doesn't have the filter
+type SymbolFilters struct {
+	Price              *PriceFilter
+	LotSize            *LotSizeFilter
+	MarketLotSize      *LotSizeFilter
+	MinNotional        *MinNotionalFilter
+	Notional           *NotionalFilter
+	PercentPrice       *PercentPriceFilter
+	PercentPriceBySide *PercentPriceBySideFilter
+	MaxNumOrders       *MaxNumOrdersFilter
+	IcebergParts       *IcebergPartsFilter
+	// every filter as sent, including the ones without a typed field
+	Raw []map[string]interface{}
+}
+
+type PriceFilter struct {
+	MinPrice decimal.Decimal
+	MaxPrice decimal.Decimal
+	TickSize decimal.Decimal
+}
+
+type LotSizeFilter struct {
+	MinQty   decimal.Decimal
+	MaxQty   decimal.Decimal
+	StepSize decimal.Decimal
+}
+
+// futures send the min in "notional"
+type MinNotionalFilter struct {
+	MinNotional   decimal.Decimal
+	ApplyToMarket bool
+	AvgPriceMins  int
+}
+
+type NotionalFilter struct {
+	MinNotional      decimal.Decimal
+	ApplyMinToMarket bool
+	MaxNotional      decimal.Decimal
+	ApplyMaxToMarket bool
+	AvgPriceMins     int
+}
+
+type PercentPriceFilter struct {
+	MultiplierUp   decimal.Decimal
+	MultiplierDown decimal.Decimal
+	AvgPriceMins   int
+}
+
+type PercentPriceBySideFilter struct {
+	BidMultiplierUp   decimal.Decimal
+	BidMultiplierDown decimal.Decimal
+	AskMultiplierUp   decimal.Decimal
+	AskMultiplierDown decimal.Decimal
+	AvgPriceMins      int
+}
+
+type MaxNumOrdersFilter struct {
+	Limit int
+}
+
+type IcebergPartsFilter struct {
+	Limit int
+}
+
+// OrderCheck is the order to check against the filters, zero values are skipped
+type OrderCheck struct {
+	Side       string
+	Type       string
+	Price      decimal.Decimal
+	Qty        decimal.Decimal
+	IcebergQty decimal.Decimal
+	// stop and take profit orders, checked against PRICE_FILTER like the price
+	StopPrice decimal.Decimal
+	// perp trailing stop
+	ActivationPrice decimal.Decimal
+	// spot market orders by quote amount, it is the notional
+	QuoteOrderQty decimal.Decimal
+	// average or mark price, needed by PERCENT_PRICE, PERCENT_PRICE_BY_SIDE and the notional of market orders,
+	// those checks are skipped without it
+	RefPrice decimal.Decimal
+}
+
+type FilterError struct {
+	Filter string
+	Reason string
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("filter failure: %s: %s", e.Filter, e.Reason)
+}
+
+type OrderValidation int
+
+const (
+	OrderValidationOff OrderValidation = iota
+	// reject the order locally if it would fail a filter
+	OrderValidationReject
+	// round price and qty to tick and step size first, then reject if it still fails
+	OrderValidationRound
+)
+
+// opt-in check of SpotPlaceOrder, SpotPlaceOrderMarket, PerpPlaceOrder and PerpPlaceOrderMarket,
+// the filters are taken from the symbol registry if set, or loaded from the exchange info on first use.
+// When the symbol has a percent price filter, or a notional filter which needs the price of a market order,
+// the spot average price or the perp mark price is fetched for the check.
+func (c *Client) SetOrderValidation(mode OrderValidation) {
+	c.validation = mode
+}
+
+// drop the cached filters, they will be loaded again on the next order
+func (c *Client) ReloadOrderFilters() {
+	c.filters.Lock()
+	defer c.filters.Unlock()
+	c.filters.data = make(map[string]map[string]*SymbolFilters)
+	c.filters.loaded = make(map[string]time.Time)
+}
+
+func (f *SymbolFilters) UnmarshalJSON(b []byte) error {
+	var raw []map[string]interface{}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	*f = SymbolFilters{Raw: raw}
+	for _, item := range raw {
+		typ, _ := item["filterType"].(string)
+		switch typ {
+		case "PRICE_FILTER":
+			f.Price = &PriceFilter{
+				MinPrice: filterDecimal(item, "minPrice"),
+				MaxPrice: filterDecimal(item, "maxPrice"),
+				TickSize: filterDecimal(item, "tickSize"),
+			}
+		case "LOT_SIZE":
+			f.LotSize = parseLotSize(item)
+		case "MARKET_LOT_SIZE":
+			f.MarketLotSize = parseLotSize(item)
+		case "MIN_NOTIONAL":
+			f.MinNotional = &MinNotionalFilter{
+				MinNotional:   filterDecimal(item, "minNotional"),
+				ApplyToMarket: filterBool(item, "applyToMarket"),
+				AvgPriceMins:  filterInt(item, "avgPriceMins"),
+			}
+			if f.MinNotional.MinNotional.IsZero() {
+				// futures check every order type against it
+				f.MinNotional.MinNotional = filterDecimal(item, "notional")
+				f.MinNotional.ApplyToMarket = true
+			}
+		case "NOTIONAL":
+			f.Notional = &NotionalFilter{
+				MinNotional:      filterDecimal(item, "minNotional"),
+				ApplyMinToMarket: filterBool(item, "applyMinToMarket"),
+				MaxNotional:      filterDecimal(item, "maxNotional"),
+				ApplyMaxToMarket: filterBool(item, "applyMaxToMarket"),
+				AvgPriceMins:     filterInt(item, "avgPriceMins"),
+			}
+		case "PERCENT_PRICE":
+			f.PercentPrice = &PercentPriceFilter{
+				MultiplierUp:   filterDecimal(item, "multiplierUp"),
+				MultiplierDown: filterDecimal(item, "multiplierDown"),
+				AvgPriceMins:   filterInt(item, "avgPriceMins"),
+			}
+		case "PERCENT_PRICE_BY_SIDE":
+			f.PercentPriceBySide = &PercentPriceBySideFilter{
+				BidMultiplierUp:   filterDecimal(item, "bidMultiplierUp"),
+				BidMultiplierDown: filterDecimal(item, "bidMultiplierDown"),
+				AskMultiplierUp:   filterDecimal(item, "askMultiplierUp"),
+				AskMultiplierDown: filterDecimal(item, "askMultiplierDown"),
+				AvgPriceMins:      filterInt(item, "avgPriceMins"),
+			}
+		case "MAX_NUM_ORDERS":
+			// spot sends maxNumOrders, futures send limit
+			limit := filterInt(item, "maxNumOrders")
+			if limit == 0 {
+				limit = filterInt(item, "limit")
+			}
+			f.MaxNumOrders = &MaxNumOrdersFilter{Limit: limit}
+		case "ICEBERG_PARTS":
+			f.IcebergParts = &IcebergPartsFilter{Limit: filterInt(item, "limit")}
+		}
+	}
+	return nil
+}
+
+func (f SymbolFilters) MarshalJSON() ([]byte, error) {
+	if f.Raw == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(f.Raw)
+}
+
+// round price to the tick size, down for buy and up for sell, and qty down to the step size
+func (f *SymbolFilters) Round(side, orderType string, price, qty decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+	return f.roundPrice(side, price), f.roundQty(f.lotSize(orderType), qty)
+}
+
+// check the order against every filter which can be checked locally, MAX_NUM_ORDERS is left to the exchange
+func (f *SymbolFilters) Validate(o OrderCheck) error {
+	side := strings.ToUpper(o.Side)
+	isMarket := strings.ToUpper(o.Type) == "MARKET"
+	for _, p := range []struct {
+		name  string
+		value decimal.Decimal
+	}{
+		{"price", o.Price},
+		{"stopPrice", o.StopPrice},
+		{"activationPrice", o.ActivationPrice},
+	} {
+		if err := f.checkPrice(p.name, p.value); err != nil {
+			return err
+		}
+	}
+	if lot := f.lotSize(o.Type); lot != nil && !o.Qty.IsZero() {
+		name := "LOT_SIZE"
+		if lot == f.MarketLotSize {
+			name = "MARKET_LOT_SIZE"
+		}
+		if lot.MinQty.IsPositive() && o.Qty.LessThan(lot.MinQty) {
+			return &FilterError{name, fmt.Sprintf("qty %s below min %s", o.Qty, lot.MinQty)}
+		}
+		if lot.MaxQty.IsPositive() && o.Qty.GreaterThan(lot.MaxQty) {
+			return &FilterError{name, fmt.Sprintf("qty %s above max %s", o.Qty, lot.MaxQty)}
+		}
+		if lot.StepSize.IsPositive() && !o.Qty.Sub(lot.MinQty).Mod(lot.StepSize).IsZero() {
+			return &FilterError{name, fmt.Sprintf("qty %s not a multiple of step size %s", o.Qty, lot.StepSize)}
+		}
+	}
+	if f.LotSize != nil && o.IcebergQty.IsPositive() && f.LotSize.StepSize.IsPositive() {
+		if !o.IcebergQty.Sub(f.LotSize.MinQty).Mod(f.LotSize.StepSize).IsZero() {
+			return &FilterError{"LOT_SIZE", fmt.Sprintf("icebergQty %s not a multiple of step size %s", o.IcebergQty, f.LotSize.StepSize)}
+		}
+	}
+	price := o.Price
+	if isMarket || price.IsZero() {
+		price = o.RefPrice
+	}
+	notional := price.Mul(o.Qty)
+	if o.QuoteOrderQty.IsPositive() {
+		notional = o.QuoteOrderQty
+	}
+	if f.MinNotional != nil && !notional.IsZero() && (!isMarket || f.MinNotional.ApplyToMarket) {
+		if notional.LessThan(f.MinNotional.MinNotional) {
+			return &FilterError{"MIN_NOTIONAL", fmt.Sprintf("notional %s below min %s", notional, f.MinNotional.MinNotional)}
+		}
+	}
+	if f.Notional != nil && !notional.IsZero() {
+		n := f.Notional
+		if (!isMarket || n.ApplyMinToMarket) && notional.LessThan(n.MinNotional) {
+			return &FilterError{"NOTIONAL", fmt.Sprintf("notional %s below min %s", notional, n.MinNotional)}
+		}
+		if (!isMarket || n.ApplyMaxToMarket) && n.MaxNotional.IsPositive() && notional.GreaterThan(n.MaxNotional) {
+			return &FilterError{"NOTIONAL", fmt.Sprintf("notional %s above max %s", notional, n.MaxNotional)}
+		}
+	}
+	if !isMarket && !o.Price.IsZero() && !o.RefPrice.IsZero() {
+		if f.PercentPrice != nil {
+			if err := checkPercentPrice("PERCENT_PRICE", o.Price, o.RefPrice, f.PercentPrice.MultiplierUp, f.PercentPrice.MultiplierDown); err != nil {
+				return err
+			}
+		}
+		if f.PercentPriceBySide != nil {
+			s := f.PercentPriceBySide
+			up, down := s.BidMultiplierUp, s.BidMultiplierDown
+			if side == "SELL" {
+				up, down = s.AskMultiplierUp, s.AskMultiplierDown
+			}
+			if err := checkPercentPrice("PERCENT_PRICE_BY_SIDE", o.Price, o.RefPrice, up, down); err != nil {
+				return err
+			}
+		}
+	}
+	if f.IcebergParts != nil && o.IcebergQty.IsPositive() && f.IcebergParts.Limit > 0 {
+		parts := o.Qty.Div(o.IcebergQty).Ceil()
+		if parts.GreaterThan(decimal.NewFromInt(int64(f.IcebergParts.Limit))) {
+			return &FilterError{"ICEBERG_PARTS", fmt.Sprintf("%s parts above limit %d", parts, f.IcebergParts.Limit)}
+		}
+	}
+	return nil
+}
+
+// internal funcs ------------------------------------------------
+
+// an unknown symbol loads the exchange info again at most once per filterMissInterval
+const filterMissInterval = time.Minute
+
+type filterCache struct {
+	sync.RWMutex
+	// product -> symbol -> filters
+	data map[string]map[string]*SymbolFilters
+	// product -> last load of the exchange info
+	loaded map[string]time.Time
+}
+
+func newFilterCache() *filterCache {
+	return &filterCache{
+		data:   make(map[string]map[string]*SymbolFilters),
+		loaded: make(map[string]time.Time),
+	}
+}
+
+// down for buy and up for sell
+func (f *SymbolFilters) roundPrice(side string, price decimal.Decimal) decimal.Decimal {
+	if f.Price == nil || price.IsZero() || !f.Price.TickSize.IsPositive() {
+		return price
+	}
+	return roundToStep(price, f.Price.MinPrice, f.Price.TickSize, strings.ToUpper(side) == "SELL")
+}
+
+func (f *SymbolFilters) roundQty(lot *LotSizeFilter, qty decimal.Decimal) decimal.Decimal {
+	if lot == nil || qty.IsZero() || !lot.StepSize.IsPositive() {
+		return qty
+	}
+	return roundToStep(qty, lot.MinQty, lot.StepSize, false)
+}
+
+func (f *SymbolFilters) checkPrice(name string, price decimal.Decimal) error {
+	if f.Price == nil || price.IsZero() {
+		return nil
+	}
+	p := f.Price
+	if p.MinPrice.IsPositive() && price.LessThan(p.MinPrice) {
+		return &FilterError{"PRICE_FILTER", fmt.Sprintf("%s %s below min %s", name, price, p.MinPrice)}
+	}
+	if p.MaxPrice.IsPositive() && price.GreaterThan(p.MaxPrice) {
+		return &FilterError{"PRICE_FILTER", fmt.Sprintf("%s %s above max %s", name, price, p.MaxPrice)}
+	}
+	if p.TickSize.IsPositive() && !price.Sub(p.MinPrice).Mod(p.TickSize).IsZero() {
+		return &FilterError{"PRICE_FILTER", fmt.Sprintf("%s %s not a multiple of tick size %s", name, price, p.TickSize)}
+	}
+	return nil
+}
+
+// MARKET_LOT_SIZE for market orders if it is set, spot often sends a zero step
+func (f *SymbolFilters) lotSize(orderType string) *LotSizeFilter {
+	if strings.ToUpper(orderType) == "MARKET" && f.MarketLotSize != nil && f.MarketLotSize.StepSize.IsPositive() {
+		return f.MarketLotSize
+	}
+	return f.LotSize
+}
+
+func roundToStep(value, min, step decimal.Decimal, up bool) decimal.Decimal {
+	if value.LessThan(min) {
+		min = decimal.Zero
+	}
+	steps := value.Sub(min).Div(step)
+	if up {
+		steps = steps.Ceil()
+	} else {
+		steps = steps.Floor()
+	}
+	return min.Add(steps.Mul(step))
+}
+
+func checkPercentPrice(name string, price, ref, up, down decimal.Decimal) error {
+	if up.IsPositive() && price.GreaterThan(ref.Mul(up)) {
+		return &FilterError{name, fmt.Sprintf("price %s above %s x %s", price, ref, up)}
+	}
+	if down.IsPositive() && price.LessThan(ref.Mul(down)) {
+		return &FilterError{name, fmt.Sprintf("price %s below %s x %s", price, ref, down)}
+	}
+	return nil
+}
+
+func parseLotSize(item map[string]interface{}) *LotSizeFilter {
+	return &LotSizeFilter{
+		MinQty:   filterDecimal(item, "minQty"),
+		MaxQty:   filterDecimal(item, "maxQty"),
+		StepSize: filterDecimal(item, "stepSize"),
+	}
+}
+
+func filterDecimal(item map[string]interface{}, key string) decimal.Decimal {
+	switch v := item[key].(type) {
+	case string:
+		d, _ := decimal.NewFromString(v)
+		return d
+	case float64:
+		return decimal.NewFromFloat(v)
+	}
+	return decimal.Zero
+}
+
+func filterInt(item map[string]interface{}, key string) int {
+	switch v := item[key].(type) {
+	case float64:
+		return int(v)
+	case string:
+		i, _ := strconv.Atoi(v)
+		return i
+	}
+	return 0
+}
+
+func filterBool(item map[string]interface{}, key string) bool {
+	b, _ := item[key].(bool)
+	return b
+}
+
+// filters of a spot or future symbol, loaded from the exchange info when missing
+func (c *Client) symbolFilters(product, symbol string) (*SymbolFilters, error) {
+	if c.registry != nil {
+		info, ok := c.registry.Symbol(product, symbol)
+		if !ok {
+			return nil, fmt.Errorf("no filters of %s %s", product, symbol)
+		}
+		return &info.Filters, nil
+	}
+	c.filters.RLock()
+	filters, ok := c.filters.data[product][symbol]
+	loaded := c.filters.loaded[product]
+	c.filters.RUnlock()
+	if ok {
+		return filters, nil
+	}
+	if time.Since(loaded) < filterMissInterval {
+		// the exchange info is fresh, the symbol is not there
+		return nil, fmt.Errorf("no filters of %s %s", product, symbol)
+	}
+	data := make(map[string]*SymbolFilters)
+	switch product {
+	case "spot":
+		info, err := c.SpotInfo()
+		if err != nil {
+			return nil, err
+		}
+		for i := range info.Symbols {
+			data[info.Symbols[i].Symbol] = &info.Symbols[i].Filters
+		}
+	case "future":
+		info, err := c.SwapInfo()
+		if err != nil {
+			return nil, err
+		}
+		for i := range info.Symbols {
+			data[info.Symbols[i].Symbol] = &info.Symbols[i].Filters
+		}
+	}
+	c.filters.Lock()
+	c.filters.data[product] = data
+	c.filters.loaded[product] = time.Now()
+	c.filters.Unlock()
+	filters, ok = data[symbol]
+	if !ok {
+		return nil, fmt.Errorf("no filters of %s %s", product, symbol)
+	}
+	return filters, nil
+}
+
+// the string fields of an order request, nil or empty is not set
+type orderFields struct {
+	side            string
+	orderType       string
+	price           *string
+	qty             *string
+	stopPrice       *string
+	activationPrice *string
+	icebergQty      *string
+	quoteOrderQty   *string
+}
+
+// validate or round the prices and qtys in place according to the validation mode
+func (c *Client) checkOrder(product, symbol string, fields orderFields) error {
+	if c.validation == OrderValidationOff {
+		return nil
+	}
+	filters, err := c.symbolFilters(product, symbol)
+	if err != nil {
+		return err
+	}
+	o := OrderCheck{
+		Side: fields.side,
+		Type: fields.orderType,
+	}
+	for _, field := range []struct {
+		name  string
+		value *string
+		dst   *decimal.Decimal
+	}{
+		{"price", fields.price, &o.Price},
+		{"qty", fields.qty, &o.Qty},
+		{"stop price", fields.stopPrice, &o.StopPrice},
+		{"activation price", fields.activationPrice, &o.ActivationPrice},
+		{"iceberg qty", fields.icebergQty, &o.IcebergQty},
+		{"quote order qty", fields.quoteOrderQty, &o.QuoteOrderQty},
+	} {
+		if field.value == nil || *field.value == "" {
+			continue
+		}
+		if *field.dst, err = decimal.NewFromString(*field.value); err != nil {
+			return fmt.Errorf("can't parse order %s", field.name)
+		}
+	}
+	if c.validation == OrderValidationRound {
+		qtyBefore := o.Qty
+		o.Price, o.Qty = filters.Round(fields.side, fields.orderType, o.Price, o.Qty)
+		if !qtyBefore.IsZero() && o.Qty.IsZero() {
+			return &FilterError{"LOT_SIZE", fmt.Sprintf("qty %s rounds to zero", qtyBefore)}
+		}
+		o.StopPrice = filters.roundPrice(fields.side, o.StopPrice)
+		o.ActivationPrice = filters.roundPrice(fields.side, o.ActivationPrice)
+		o.IcebergQty = filters.roundQty(filters.LotSize, o.IcebergQty)
+		for _, field := range []struct {
+			dst   *string
+			value decimal.Decimal
+		}{
+			{fields.price, o.Price},
+			{fields.qty, o.Qty},
+			{fields.stopPrice, o.StopPrice},
+			{fields.activationPrice, o.ActivationPrice},
+			{fields.icebergQty, o.IcebergQty},
+		} {
+			if field.dst != nil && *field.dst != "" && !field.value.IsZero() {
+				*field.dst = field.value.String()
+			}
+		}
+	}
+	if filters.needRefPrice(o) {
+		if o.RefPrice, err = c.refPrice(product, symbol); err != nil {
+			return err
+		}
+	}
+	return filters.Validate(o)
+}
+
+// the checks of Validate which are skipped without RefPrice
+func (f *SymbolFilters) needRefPrice(o OrderCheck) bool {
+	isMarket := strings.ToUpper(o.Type) == "MARKET"
+	if !isMarket && !o.Price.IsZero() && (f.PercentPrice != nil || f.PercentPriceBySide != nil) {
+		return true
+	}
+	if !o.Price.IsZero() || o.Qty.IsZero() || o.QuoteOrderQty.IsPositive() {
+		return false
+	}
+	if f.MinNotional != nil && (!isMarket || f.MinNotional.ApplyToMarket) {
+		return true
+	}
+	return f.Notional != nil && (!isMarket || f.Notional.ApplyMinToMarket || f.Notional.ApplyMaxToMarket)
+}
+
+// average price of spot, mark price of futures
+func (c *Client) refPrice(product, symbol string) (decimal.Decimal, error) {
+	var price string
+	switch product {
+	case "spot":
+		res, err := c.SpotAvgPrice(symbol)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		price = res.Price
+	case "future":
+		res, err := c.SwapMarkPrice(symbol)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		price = res.MarkPrice
+	default:
+		return decimal.Zero, nil
+	}
+	ref, err := decimal.NewFromString(price)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("can't parse reference price of %s %s", product, symbol)
+	}
+	return ref, nil
+}
@@ -2,6 +2,7 @@ package bnnapi
 
 import (
 	"net/http"
+	"strings"
 )
 
 func (b *Client) FundingRateHistory(symbol string, limit int, start, end int64) ([]*FundingData, error) {
@@ -62,15 +63,14 @@ type MarkPriceData struct {
 	Time            int64  `json:"time"`
 }
 
+// one page of incomes, method is one of the IncomeType and empty for every type. IncomeHistory walks a whole time range.
 func (b *Client) GetIncomeHistory(method, symbol string, limit int, start, end int64) ([]*IncomeResponse, error) {
 	opts := IncomeHisOpts{
-		Symbol:     symbol,
+		Symbol:     strings.ToUpper(symbol),
 		Limit:      limit,
-		IncomeType: method,
-	}
-	if start != 0 && end != 0 {
-		opts.StartTime = start
-		opts.EndTime = end
+		IncomeType: strings.ToUpper(method),
+		StartTime:  start,
+		EndTime:    end,
 	}
 	if opts.Limit == 0 || opts.Limit > 1000 {
 		opts.Limit = 1000
@@ -88,8 +88,8 @@ func (b *Client) GetIncomeHistory(method, symbol string, limit int, start, end i
 }
 
 type IncomeHisOpts struct {
-	Symbol     string `url:"symbol"`
-	IncomeType string `url:"incomeType"`
+	Symbol     string `url:"symbol,omitempty"`
+	IncomeType string `url:"incomeType,omitempty"`
 	StartTime  int64  `url:"startTime,omitempty"`
 	EndTime    int64  `url:"endTime,omitempty"`
 	Limit      int    `url:"limit"`
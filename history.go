@@ -0,0 +1,132 @@
+package bnnapi
+
+import (
+	"errors"
+	"time"
+)
+
+// the most a single request of the history endpoints can span
+const (
+	spotHistoryWindow = 24 * time.Hour
+	perpHistoryWindow = 7 * 24 * time.Hour
+)
+
+var errHistoryStart = errors.New("start time is needed to walk the history by time")
+
+type historyKey struct {
+	ID   int64
+	Time int64
+}
+
+type historyPage struct {
+	FromID    int64
+	StartTime int64
+	EndTime   int64
+}
+
+// historyCursor walks a history endpoint page by page, shared by the history iterators.
+// With a from id it goes up by id, otherwise by windows from start to end.
+type historyCursor struct {
+	byID   bool
+	limit  int
+	window time.Duration
+	// next id when walking by id
+	nextID int64
+	// in milliseconds, start moves forward as the pages come in
+	start int64
+	end   int64
+	// ids already seen at the start time, the next page starts at that very millisecond again
+	seen map[int64]bool
+	done bool
+}
+
+func newHistoryCursorByID(fromID int64, start, end time.Time, limit int) *historyCursor {
+	c := newHistoryCursor(start, end, 0, limit)
+	c.byID = true
+	c.nextID = fromID
+	return c
+}
+
+// zero end is now
+func newHistoryCursor(start, end time.Time, window time.Duration, limit int) *historyCursor {
+	if end.IsZero() {
+		end = time.Now()
+	}
+	c := &historyCursor{
+		limit:  limit,
+		window: window,
+		end:    end.UnixMilli(),
+		seen:   make(map[int64]bool),
+	}
+	if !start.IsZero() {
+		c.start = start.UnixMilli()
+	}
+	return c
+}
+
+// params of the next request, false once the history is walked through
+func (c *historyCursor) page() (historyPage, bool) {
+	if c.done {
+		return historyPage{}, false
+	}
+	if c.byID {
+		return historyPage{FromID: c.nextID}, true
+	}
+	windowEnd := c.start + c.window.Milliseconds() - 1
+	if windowEnd > c.end {
+		windowEnd = c.end
+	}
+	return historyPage{StartTime: c.start, EndTime: windowEnd}, true
+}
+
+// move past the page, keep tells which records of the page are new and in range
+func (c *historyCursor) advance(keys []historyKey) (keep []bool) {
+	keep = make([]bool, len(keys))
+	if c.byID {
+		for i, key := range keys {
+			if key.ID >= c.nextID {
+				c.nextID = key.ID + 1
+			}
+			keep[i] = key.Time >= c.start && key.Time <= c.end
+			if key.Time > c.end {
+				// past the end, the ids only go up from here
+				c.done = true
+			}
+		}
+		if len(keys) < c.limit {
+			c.done = true
+		}
+		return keep
+	}
+	page, _ := c.page()
+	latest := c.start
+	for i, key := range keys {
+		keep[i] = !(key.Time == c.start && c.seen[key.ID])
+		if key.Time > latest {
+			latest = key.Time
+		}
+	}
+	if len(keys) < c.limit {
+		// the window is done
+		c.start = page.EndTime + 1
+		c.seen = make(map[int64]bool)
+		if c.start > c.end {
+			c.done = true
+		}
+		return keep
+	}
+	if latest == c.start {
+		// a full page within one millisecond, nothing more to do than moving on
+		c.start++
+		c.seen = make(map[int64]bool)
+		return keep
+	}
+	c.start = latest
+	c.seen = make(map[int64]bool)
+	for _, key := range keys {
+		if key.Time == latest {
+			c.seen[key.ID] = true
+		}
+	}
+	return keep
+}
@@ -0,0 +1,185 @@
+package bnnapi
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type IncomeType string
+
+const (
+	IncomeTransfer                  IncomeType = "TRANSFER"
+	IncomeWelcomeBonus              IncomeType = "WELCOME_BONUS"
+	IncomeRealizedPnl               IncomeType = "REALIZED_PNL"
+	IncomeFundingFee                IncomeType = "FUNDING_FEE"
+	IncomeCommission                IncomeType = "COMMISSION"
+	IncomeInsuranceClear            IncomeType = "INSURANCE_CLEAR"
+	IncomeReferralKickback          IncomeType = "REFERRAL_KICKBACK"
+	IncomeCommissionRebate          IncomeType = "COMMISSION_REBATE"
+	IncomeAPIRebate                 IncomeType = "API_REBATE"
+	IncomeContestReward             IncomeType = "CONTEST_REWARD"
+	IncomeCrossCollateralTransfer   IncomeType = "CROSS_COLLATERAL_TRANSFER"
+	IncomeOptionsPremiumFee         IncomeType = "OPTIONS_PREMIUM_FEE"
+	IncomeOptionsSettleProfit       IncomeType = "OPTIONS_SETTLE_PROFIT"
+	IncomeInternalTransfer          IncomeType = "INTERNAL_TRANSFER"
+	IncomeAutoExchange              IncomeType = "AUTO_EXCHANGE"
+	IncomeDeliveredSettlement       IncomeType = "DELIVERED_SETTELMENT"
+	IncomeCoinSwapDeposit           IncomeType = "COIN_SWAP_DEPOSIT"
+	IncomeCoinSwapWithdraw          IncomeType = "COIN_SWAP_WITHDRAW"
+	IncomePositionLimitIncreaseFee  IncomeType = "POSITION_LIMIT_INCREASE_FEE"
+	IncomeStrategyUMFuturesTransfer IncomeType = "STRATEGY_UMFUTURES_TRANSFER"
+	IncomeFeeReturn                 IncomeType = "FEE_RETURN"
+	IncomeBFUSDReward               IncomeType = "BFUSD_REWARD"
+)
+
+// IncomeIterator walks the incomes page by page, used the same way as SpotOrderIterator
+type IncomeIterator struct {
+	client     *Client
+	symbol     string
+	incomeType IncomeType
+	cursor     *historyCursor
+	buf        []*IncomeResponse
+	cur        *IncomeResponse
+	err        error
+}
+
+// walk by 7 day windows from start to end, empty symbol and incomeType for all of them.
+// Zero end is now.
+func (b *Client) IncomeHistory(symbol string, incomeType IncomeType, start, end time.Time) *IncomeIterator {
+	it := &IncomeIterator{
+		client:     b,
+		symbol:     strings.ToUpper(symbol),
+		incomeType: incomeType,
+		cursor:     newHistoryCursor(start, end, perpHistoryWindow, 1000),
+	}
+	if start.IsZero() {
+		it.err = errHistoryStart
+	}
+	return it
+}
+
+func (it *IncomeIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.err != nil {
+			return false
+		}
+		page, ok := it.cursor.page()
+		if !ok {
+			return false
+		}
+		incomes, err := it.client.GetIncomeHistory(string(it.incomeType), it.symbol, it.cursor.limit, page.StartTime, page.EndTime)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		keys := make([]historyKey, len(incomes))
+		for i, income := range incomes {
+			keys[i] = historyKey{ID: incomeKey(income), Time: income.Time}
+		}
+		for i, keep := range it.cursor.advance(keys) {
+			if keep {
+				it.buf = append(it.buf, incomes[i])
+			}
+		}
+	}
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+func (it *IncomeIterator) Income() *IncomeResponse {
+	return it.cur
+}
+
+// the error which stopped the walk, nil when it's done
+func (it *IncomeIterator) Err() error {
+	return it.err
+}
+
+// walk the rest of the range at once
+func (it *IncomeIterator) All() ([]*IncomeResponse, error) {
+	var incomes []*IncomeResponse
+	for it.Next() {
+		incomes = append(incomes, it.Income())
+	}
+	return incomes, it.Err()
+}
+
+// the incomes come in different assets, so the totals are per asset as well
+type IncomeTotal struct {
+	// symbol, day or income type
+	Key   string
+	Asset string
+	Total decimal.Decimal
+	Count int
+}
+
+// sorted by symbol and asset, transfers and other incomes without a symbol are under ""
+func IncomeBySymbol(incomes []*IncomeResponse) []IncomeTotal {
+	return sumIncome(incomes, func(income *IncomeResponse) string {
+		return income.Symbol
+	})
+}
+
+// sorted by day and asset, the day is "2006-01-02" in loc and UTC when loc is nil
+func IncomeByDay(incomes []*IncomeResponse, loc *time.Location) []IncomeTotal {
+	if loc == nil {
+		loc = time.UTC
+	}
+	return sumIncome(incomes, func(income *IncomeResponse) string {
+		return time.UnixMilli(income.Time).In(loc).Format("2006-01-02")
+	})
+}
+
+// sorted by income type and asset
+func IncomeByType(incomes []*IncomeResponse) []IncomeTotal {
+	return sumIncome(incomes, func(income *IncomeResponse) string {
+		return income.IncomeType
+	})
+}
+
+// internal funcs ------------------------------------------------
+
+// a trade has its realized pnl and commission under the same tranId
+func incomeKey(income *IncomeResponse) int64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%d|%s|%s|%s|%s", income.TranID, income.IncomeType, income.Symbol, income.Asset, income.TradeID)
+	return int64(h.Sum64())
+}
+
+func sumIncome(incomes []*IncomeResponse, key func(*IncomeResponse) string) []IncomeTotal {
+	type group struct {
+		key   string
+		asset string
+	}
+	totals := make(map[group]*IncomeTotal)
+	for _, income := range incomes {
+		amount, err := decimal.NewFromString(income.Income)
+		if err != nil {
+			continue
+		}
+		g := group{key: key(income), asset: income.Asset}
+		total, ok := totals[g]
+		if !ok {
+			total = &IncomeTotal{Key: g.key, Asset: g.asset}
+			totals[g] = total
+		}
+		total.Total = total.Total.Add(amount)
+		total.Count++
+	}
+	out := make([]IncomeTotal, 0, len(totals))
+	for _, total := range totals {
+		out = append(out, *total)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Key != out[j].Key {
+			return out[i].Key < out[j].Key
+		}
+		return out[i].Asset < out[j].Asset
+	})
+	return out
+}
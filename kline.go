@@ -4,9 +4,97 @@ import (
 	"fmt"
 	"net/http"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
-func (b *Client) SpotKlines(symbol, interval string, limit int, start, end time.Time) ([][]interface{}, error) {
+func (b *Client) SpotKlines(symbol, interval string, limit int, start, end time.Time) ([]Klines, error) {
+	return b.klines("spot", "api/v3/klines", symbol, interval, limit, start, end)
+}
+
+func (b *Client) SwapKlines(symbol, interval string, limit int, start, end time.Time) ([]Klines, error) {
+	return b.klines("future", "fapi/v1/klines", symbol, interval, limit, start, end)
+}
+
+// page through [start, end] 1000 bars per request, start is needed and zero end is now, e.g.
+//
+//	it := client.SpotKlinesRange("BTCUSDT", "1m", start, end)
+//	for it.Next() {
+//		kline := it.Kline()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+func (b *Client) SpotKlinesRange(symbol, interval string, start, end time.Time) *KlineIterator {
+	return newKlineIterator(b, "spot", "api/v3/klines", symbol, interval, start, end)
+}
+
+func (b *Client) SwapKlinesRange(symbol, interval string, start, end time.Time) *KlineIterator {
+	return newKlineIterator(b, "future", "fapi/v1/klines", symbol, interval, start, end)
+}
+
+type KlinesOpts struct {
+	Symbol    string `url:"symbol"`   // Symbol is the symbol to fetch data for
+	Interval  string `url:"interval"` // Interval is the interval for each kline/candlestick
+	Limit     int    `url:"limit"`    // Limit is the maximal number of elements to receive. Max 500
+	StartTime int64  `url:"startTime,omitempty"`
+	EndTime   int64  `url:"endTime,omitempty"`
+}
+
+type Klines struct {
+	OpenTime                 time.Time
+	OpenPrice                decimal.Decimal
+	High                     decimal.Decimal
+	Low                      decimal.Decimal
+	ClosePrice               decimal.Decimal
+	Volume                   decimal.Decimal
+	CloseTime                time.Time
+	QuoteAssetVolume         decimal.Decimal
+	Trades                   int
+	TakerBuyBaseAssetVolume  decimal.Decimal
+	TakerBuyQuoteAssetVolume decimal.Decimal
+}
+
+type KlineIterator struct {
+	client   *Client
+	product  string
+	path     string
+	symbol   string
+	interval string
+	next     time.Time
+	end      time.Time
+	buf      []Klines
+	current  Klines
+	last     time.Time
+	done     bool
+	err      error
+}
+
+// move to the next kline, false when the range is done or an error happened
+func (it *KlineIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+	it.current = it.buf[0]
+	it.buf = it.buf[1:]
+	return true
+}
+
+func (it *KlineIterator) Kline() Klines {
+	return it.current
+}
+
+func (it *KlineIterator) Err() error {
+	return it.err
+}
+
+// internal funcs ------------------------------------------------
+
+const klinesPageLimit = 1000
+
+func (b *Client) klines(product, path, symbol, interval string, limit int, start, end time.Time) ([]Klines, error) {
 	opts := KlinesOpts{
 		Symbol:   symbol,
 		Interval: interval,
@@ -19,70 +107,126 @@ func (b *Client) SpotKlines(symbol, interval string, limit int, start, end time.
 	if opts.Symbol == "" || opts.Interval == "" {
 		return nil, fmt.Errorf("symbol or interval are missing")
 	}
-	if opts.Limit == 0 || opts.Limit > 1000 {
-		opts.Limit = 1000
+	if opts.Limit == 0 || opts.Limit > klinesPageLimit {
+		opts.Limit = klinesPageLimit
 	}
-	res, err := b.do("spot", http.MethodGet, "api/v3/klines", opts, false, false)
+	res, err := b.do(product, http.MethodGet, path, opts, false, false)
 	if err != nil {
 		return nil, err
 	}
-	var klines [][]interface{}
-	err = json.Unmarshal(res, &klines)
+	var raw [][]interface{}
+	err = json.Unmarshal(res, &raw)
 	if err != nil {
 		return nil, err
 	}
-	return klines, nil
+	return parseKlines(raw)
+}
 
+func parseKlines(raw [][]interface{}) ([]Klines, error) {
+	klines := make([]Klines, 0, len(raw))
+	for _, item := range raw {
+		if len(item) < 11 {
+			return nil, fmt.Errorf("unexpected kline length %d", len(item))
+		}
+		var k Klines
+		var err error
+		openTime, ok := item[0].(float64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected kline open time: %v", item[0])
+		}
+		closeTime, ok := item[6].(float64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected kline close time: %v", item[6])
+		}
+		trades, ok := item[8].(float64)
+		if !ok {
+			return nil, fmt.Errorf("unexpected kline trades: %v", item[8])
+		}
+		k.OpenTime = time.UnixMilli(int64(openTime))
+		k.CloseTime = time.UnixMilli(int64(closeTime))
+		k.Trades = int(trades)
+		fields := []struct {
+			idx int
+			dst *decimal.Decimal
+		}{
+			{1, &k.OpenPrice},
+			{2, &k.High},
+			{3, &k.Low},
+			{4, &k.ClosePrice},
+			{5, &k.Volume},
+			{7, &k.QuoteAssetVolume},
+			{9, &k.TakerBuyBaseAssetVolume},
+			{10, &k.TakerBuyQuoteAssetVolume},
+		}
+		for _, field := range fields {
+			str, ok := item[field.idx].(string)
+			if !ok {
+				return nil, fmt.Errorf("unexpected kline field %d: %v", field.idx, item[field.idx])
+			}
+			*field.dst, err = decimal.NewFromString(str)
+			if err != nil {
+				return nil, err
+			}
+		}
+		klines = append(klines, k)
+	}
+	return klines, nil
 }
 
-func (b *Client) SwapKlines(symbol, interval string, limit int, start, end time.Time) ([][]interface{}, error) {
-	opts := KlinesOpts{
-		Symbol:   symbol,
-		Interval: interval,
-		Limit:    limit,
+func newKlineIterator(client *Client, product, path, symbol, interval string, start, end time.Time) *KlineIterator {
+	it := &KlineIterator{
+		client:   client,
+		product:  product,
+		path:     path,
+		symbol:   symbol,
+		interval: interval,
+		next:     start,
+		end:      end,
 	}
-	if start.IsZero() == false && end.IsZero() == false {
-		opts.StartTime = start.UnixNano() / int64(time.Millisecond)
-		opts.EndTime = end.UnixNano() / int64(time.Millisecond)
+	if end.IsZero() {
+		it.end = time.Now()
 	}
-	if opts.Symbol == "" || opts.Interval == "" {
-		return nil, fmt.Errorf("symbol or interval are missing")
+	if start.IsZero() {
+		// klines without startTime are only the latest page
+		it.err = errHistoryStart
 	}
-	if opts.Limit == 0 || opts.Limit > 1000 {
-		opts.Limit = 1000
+	return it
+}
+
+func (it *KlineIterator) fetch() {
+	if it.next.After(it.end) {
+		it.done = true
+		return
 	}
-	res, err := b.do("future", http.MethodGet, "fapi/v1/klines", opts, false, false)
+	page, err := it.client.klines(it.product, it.path, it.symbol, it.interval, klinesPageLimit, it.next, it.end)
 	if err != nil {
-		return nil, err
+		it.err = err
+		return
 	}
-	var klines [][]interface{}
-	err = json.Unmarshal(res, &klines)
-	if err != nil {
-		return nil, err
+	if len(page) < klinesPageLimit {
+		it.done = true
 	}
-	return klines, nil
-
-}
-
-type KlinesOpts struct {
-	Symbol    string `url:"symbol"`   // Symbol is the symbol to fetch data for
-	Interval  string `url:"interval"` // Interval is the interval for each kline/candlestick
-	Limit     int    `url:"limit"`    // Limit is the maximal number of elements to receive. Max 500
-	StartTime int64  `url:"startTime,omitempty"`
-	EndTime   int64  `url:"endTime,omitempty"`
-}
-
-type Klines struct {
-	OpenTime                 int64
-	OpenPrice                string
-	High                     string
-	Low                      string
-	ClosePrice               string
-	Volume                   string
-	CloseTime                int64
-	QuoteAssetVolume         string
-	Trades                   int
-	TakerBuyBaseAssetVolume  string
-	TakerBuyQuoteAssetVolume string
-	Ignore                   string
+	for _, k := range page {
+		// the bar on the page boundary could come twice
+		if !it.last.IsZero() && !k.OpenTime.After(it.last) {
+			continue
+		}
+		if k.OpenTime.After(it.end) {
+			it.done = true
+			break
+		}
+		it.buf = append(it.buf, k)
+		it.last = k.OpenTime
+	}
+	if len(page) == 0 {
+		it.done = true
+		return
+	}
+	next := page[len(page)-1].OpenTime.Add(time.Millisecond)
+	if !next.After(it.next) {
+		// no progress, avoid looping on the same page
+		it.done = true
+		return
+	}
+	it.next = next
 }
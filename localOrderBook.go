@@ -24,14 +24,15 @@ type OrderBookBranch struct {
 	toLevel       int
 	reCh          chan error
 	lastRefresh   lastRefreshBranch
+	endpoints     Endpoints
 }
 
-func SpotLocalOrderBook(symbol string, logger *log.Logger) *OrderBookBranch {
-	return localOrderBook("spot", symbol, logger)
+func SpotLocalOrderBook(symbol string, logger *log.Logger, opts ...StreamOption) *OrderBookBranch {
+	return localOrderBook("spot", symbol, logger, newStreamConfig(opts))
 }
 
-func PerpLocalOrderBook(symbol string, logger *log.Logger) *OrderBookBranch {
-	return localOrderBook("perp", symbol, logger)
+func PerpLocalOrderBook(symbol string, logger *log.Logger, opts ...StreamOption) *OrderBookBranch {
+	return localOrderBook("perp", symbol, logger, newStreamConfig(opts))
 }
 
 func (o *OrderBookBranch) RefreshLocalOrderBook(err error) error {
@@ -130,8 +131,10 @@ func (o *OrderBookBranch) ifCanRefresh() bool {
 }
 
 // logurs as log system
-func (o *OrderBookBranch) getOrderBookSnapShot(product, symbol string) error {
+func (o *OrderBookBranch) getOrderBookSnapShot(ctx context.Context, product, symbol string) error {
 	client := New("", "", "")
+	client.SetEndpoints(o.endpoints)
+	client = client.WithContext(ctx)
 	switch product {
 	case "spot":
 		res, err := client.SpotDepth(symbol, 5000)
@@ -308,9 +311,10 @@ func reStartMainSeesionErrHub(err string) bool {
 	return true
 }
 
-func localOrderBook(product, symbol string, logger *log.Logger) *OrderBookBranch {
+func localOrderBook(product, symbol string, logger *log.Logger, config streamConfig) *OrderBookBranch {
 	var o OrderBookBranch
 	o.SetLookBackSec(5)
+	o.endpoints = config.endpoints
 	ctx, cancel := context.WithCancel(context.Background())
 	o.cancel = &cancel
 	bookticker := make(chan map[string]interface{}, 50)
@@ -325,7 +329,7 @@ func localOrderBook(product, symbol string, logger *log.Logger) *OrderBookBranch
 			case <-ctx.Done():
 				return
 			default:
-				if err := binanceSocket(ctx, product, symbol, "@depth@100ms", logger, &bookticker, &orderBookErr); err == nil {
+				if err := binanceSocket(ctx, o.endpoints, product, symbol, "@depth@100ms", logger, &bookticker, &orderBookErr); err == nil {
 					return
 				} else {
 					if reStartMainSeesionErrHub(err.Error()) {
@@ -372,7 +376,7 @@ func (o *OrderBookBranch) maintainOrderBook(
 	go func() {
 		// avoid latancy issue
 		time.Sleep(time.Second * 3)
-		if err := o.getOrderBookSnapShot(product, symbol); err != nil {
+		if err := o.getOrderBookSnapShot(ctx, product, symbol); err != nil {
 			snapshotErr <- err
 		}
 	}()
@@ -512,18 +516,13 @@ func decodingMap(message []byte, logger *log.Logger) (res map[string]interface{}
 	return res, nil
 }
 
-func binanceSocket(ctx context.Context, product, symbol, channel string, logger *log.Logger, mainCh *chan map[string]interface{}, reCh *chan error) error {
+func binanceSocket(ctx context.Context, endpoints Endpoints, product, symbol, channel string, logger *log.Logger, mainCh *chan map[string]interface{}, reCh *chan error) error {
 	var w wS
 	var duration time.Duration = 300
 	w.Channel = channel
 	w.Logger = logger
 	var buffer bytes.Buffer
-	switch product {
-	case "spot":
-		buffer.WriteString("wss://stream.binance.com:9443/ws/")
-	case "perp":
-		buffer.WriteString("wss://fstream.binance.com/ws/")
-	}
+	buffer.WriteString(endpoints.stream(product))
 	buffer.WriteString(strings.ToLower(symbol))
 	buffer.WriteString(w.Channel)
 	url := buffer.String()
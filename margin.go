@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 func (b *Client) MarginTransfer(method, asset string, amount float64) (*TransferResponse, error) {
@@ -206,6 +208,43 @@ type IsoAccountAssetInfo struct {
 	TotalAsset    string `json:"totalAsset"`
 }
 
+type IsolatedAccountOpts struct {
+	// max 5 symbols, comma separated
+	Symbols string `url:"symbols"`
+}
+
+// the isolated margin account of a symbol
+func (b *Client) MarginIsolatedSymbolAccount(symbol string) (*IsoAccountTotalInfo, error) {
+	opts := IsolatedAccountOpts{
+		Symbols: strings.ToUpper(symbol),
+	}
+	res, err := b.do("spot", http.MethodGet, "sapi/v1/margin/isolated/account", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := &isolatedAccountResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	for i := range resp.Assets {
+		if resp.Assets[i].Symbol == opts.Symbols {
+			info := resp.Assets[i].IsoAccountTotalInfo
+			info.TradeEnabled = strconv.FormatBool(resp.Assets[i].TradeEnabled)
+			return &info, nil
+		}
+	}
+	return nil, fmt.Errorf("no isolated margin account of %s", opts.Symbols)
+}
+
+// tradeEnabled is sent as a bool, IsoAccountTotalInfo keeps it as a string
+type isolatedAccountResponse struct {
+	Assets []struct {
+		IsoAccountTotalInfo
+		TradeEnabled bool `json:"tradeEnabled"`
+	} `json:"assets"`
+}
+
 func (b *Client) InterestHistory(isosymbol string) (*InterestHistoryResponse, error) {
 	opts := InterestHistoryOpts{
 		IsoSymbol: isosymbol,
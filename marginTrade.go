@@ -34,6 +34,9 @@ func (b *Client) MarginPlaceOrder(symbol, side string, price, size string, order
 		Isolated:    isolated,
 		TimeInForce: utif,
 	}
+	if b.dryRun.enabled {
+		return b.dryRunMarginOrder(opts)
+	}
 	res, err := b.do("spot", http.MethodPost, "sapi/v1/margin/order", opts, true, false)
 	if err != nil {
 		return nil, err
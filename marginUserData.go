@@ -0,0 +1,637 @@
+package bnnapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+type MarginBalance struct {
+	Asset    string
+	Free     decimal.Decimal
+	Locked   decimal.Decimal
+	Borrowed decimal.Decimal
+	Interest decimal.Decimal
+	NetAsset decimal.Decimal
+}
+
+// free and locked are live from the stream, borrowed and interest come with the rest snapshots
+// which are taken every interval and right after a liability change
+type MarginAccountData struct {
+	// empty for cross margin
+	Symbol      string
+	MarginLevel decimal.Decimal
+	// EXCESSIVE, NORMAL, MARGIN_CALL, PRE_LIQUIDATION or FORCE_LIQUIDATION, empty until known
+	MarginLevelStatus string
+	// sorted by asset
+	Balances   []MarginBalance
+	UpdateTime time.Time
+}
+
+// MARGIN_LEVEL_STATUS_CHANGE event
+type MarginLevelUpdate struct {
+	MarginLevel decimal.Decimal
+	Status      string
+	EventTime   time.Time
+}
+
+// USER_LIABILITY_CHANGE event, sent when the account borrows or is charged interest
+type MarginLiabilityUpdate struct {
+	Asset string
+	// BORROW
+	Type           string
+	TransactionID  int64
+	Principal      decimal.Decimal
+	Interest       decimal.Decimal
+	TotalLiability decimal.Decimal
+	EventTime      time.Time
+}
+
+// one event of a margin user data stream, only the field of its Type is set
+type MarginUserEvent struct {
+	// executionReport, balanceUpdate, listStatus, MARGIN_LEVEL_STATUS_CHANGE, USER_LIABILITY_CHANGE or listenKeyExpired
+	Type        string
+	EventTime   time.Time
+	Order       *SpotOrderUpdate
+	Balance     *SpotBalanceUpdate
+	OrderList   *OrderListUpdate
+	MarginLevel *MarginLevelUpdate
+	Liability   *MarginLiabilityUpdate
+}
+
+type marginUsersBranch struct {
+	sync.Mutex
+	users map[string]*marginUserDataBranch
+}
+
+type marginUserDataBranch struct {
+	isoSymbol          string
+	account            marginAccountBranch
+	cancel             *context.CancelFunc
+	httpUpdateInterval marginIntervalBranch
+	errs               chan error
+	trades             userTradesBranch
+	events             marginEventsBranch
+	link               streamLinkBranch
+	// take a snapshot now, after a liability change
+	snapshot chan struct{}
+}
+
+type marginAccountBranch struct {
+	sync.RWMutex
+	Data *MarginAccountData
+}
+
+// seconds between the rest snapshots, changed tells the running ticker
+type marginIntervalBranch struct {
+	sync.Mutex
+	seconds int
+	changed chan struct{}
+}
+
+type marginEventsBranch struct {
+	sync.Mutex
+	subs   []chan MarginUserEvent
+	closed bool
+}
+
+// start the private channel of cross margin with empty isoSymbol, or of the isolated margin of isoSymbol.
+// Every isolated symbol has a channel of its own.
+func (c *Client) InitMarginPrivateChannel(isoSymbol string, logger *log.Logger) {
+	c.marginLocalUserData(strings.ToUpper(isoSymbol), logger)
+}
+
+func (c *Client) CloseMarginUserData(isoSymbol string) {
+	isoSymbol = strings.ToUpper(isoSymbol)
+	c.marginUsers.Lock()
+	u, ok := c.marginUsers.users[isoSymbol]
+	delete(c.marginUsers.users, isoSymbol)
+	c.marginUsers.Unlock()
+	if !ok {
+		return
+	}
+	(*u.cancel)()
+	u.events.close()
+	u.trades.Lock()
+	defer u.trades.Unlock()
+	u.trades.data = []TradeData{}
+}
+
+// default is 60 sec, takes effect on the running channel
+func (c *Client) SetMarginHttpUpdateInterval(isoSymbol string, input int) {
+	if input <= 0 {
+		return
+	}
+	if u, ok := c.marginUser(isoSymbol); ok {
+		u.httpUpdateInterval.set(input)
+	}
+}
+
+// a copy of the live account, nil before the first snapshot
+func (c *Client) GetMarginAccountData(isoSymbol string) (*MarginAccountData, error) {
+	u, ok := c.marginUser(isoSymbol)
+	if !ok {
+		return nil, errors.New("margin private channel is not started")
+	}
+	u.account.RLock()
+	defer u.account.RUnlock()
+	if u.account.Data == nil {
+		return nil, u.readerrs()
+	}
+	data := *u.account.Data
+	data.Balances = append([]MarginBalance(nil), u.account.Data.Balances...)
+	return &data, u.readerrs()
+}
+
+func (c *Client) ReadMarginUserTrade(isoSymbol string) []TradeData {
+	u, ok := c.marginUser(isoSymbol)
+	if !ok {
+		return nil
+	}
+	u.trades.Lock()
+	defer u.trades.Unlock()
+	trades := u.trades.data
+	u.trades.data = []TradeData{}
+	return trades
+}
+
+// every event in the order of the stream, the channel is closed by CloseMarginUserData.
+// The stream never waits for a subscriber, one whose buffer is full is closed and dropped,
+// subscribe again and resync from GetMarginAccountData then.
+func (c *Client) SubscribeMarginUserEvents(isoSymbol string, buffer int) <-chan MarginUserEvent {
+	u, ok := c.marginUser(isoSymbol)
+	if !ok {
+		ch := make(chan MarginUserEvent)
+		close(ch)
+		return ch
+	}
+	return u.events.subscribe(buffer)
+}
+
+// internal funcs ------------------------------------------------
+
+func (c *Client) marginUser(isoSymbol string) (*marginUserDataBranch, bool) {
+	c.marginUsers.Lock()
+	defer c.marginUsers.Unlock()
+	u, ok := c.marginUsers.users[strings.ToUpper(isoSymbol)]
+	return u, ok
+}
+
+// "margin" or "isomargin"
+func (u *marginUserDataBranch) product() string {
+	if u.isoSymbol == "" {
+		return "margin"
+	}
+	return "isomargin"
+}
+
+// default errs cap 5, trades cap 100
+func (c *Client) marginLocalUserData(isoSymbol string, logger *log.Logger) {
+	u := &marginUserDataBranch{
+		isoSymbol: isoSymbol,
+		httpUpdateInterval: marginIntervalBranch{
+			seconds: 60,
+			changed: make(chan struct{}, 1),
+		},
+		errs:     make(chan error, 5),
+		snapshot: make(chan struct{}, 1),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	u.cancel = &cancel
+	u.link.alive()
+	c.marginUsers.Lock()
+	if old, ok := c.marginUsers.users[isoSymbol]; ok {
+		// one channel per account
+		(*old.cancel)()
+		old.events.close()
+	}
+	c.marginUsers.users[isoSymbol] = u
+	c.marginUsers.Unlock()
+	userData := make(chan map[string]interface{}, 100)
+	// stream user data
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				res, err := c.WithContext(ctx).GetListenKeyHub(u.product(), isoSymbol)
+				if err != nil {
+					logger.Println("retry listen key for margin user data stream in 5 sec..")
+					time.Sleep(time.Second * 5)
+					continue
+				}
+				if err := c.marginUserData(ctx, u, res.ListenKey, logger, &userData); err == nil {
+					return
+				}
+				time.Sleep(time.Second)
+			}
+		}
+	}()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			default:
+				if err := u.maintainUserData(ctx, c, &userData); err == nil {
+					return
+				} else {
+					logger.Warningf("Refreshing margin private channel with err: %s, retry in 5 sec..\n", err.Error())
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(time.Second * 5):
+				}
+			}
+		}
+	}()
+	// wait for connecting
+	time.Sleep(time.Second * 5)
+}
+
+func (u *marginUserDataBranch) getAccountSnapShot(client *Client) error {
+	data := &MarginAccountData{
+		Symbol:     u.isoSymbol,
+		UpdateTime: time.Now(),
+	}
+	if u.isoSymbol == "" {
+		res, err := client.MarginAccount()
+		if err != nil {
+			return err
+		}
+		data.MarginLevel, _ = decimal.NewFromString(res.MarginLevel)
+		for _, asset := range res.UserAssets {
+			data.Balances = append(data.Balances, marginBalance(asset.Asset, asset.Free, asset.Locked, asset.Borrowed, asset.Interest, asset.NetAsset))
+		}
+	} else {
+		res, err := client.MarginIsolatedSymbolAccount(u.isoSymbol)
+		if err != nil {
+			return err
+		}
+		data.MarginLevel, _ = decimal.NewFromString(res.MarginLevel)
+		data.MarginLevelStatus = res.MarginLevelStatus
+		for _, asset := range []IsoAccountAssetInfo{res.BaseAsset, res.QuoteAsset} {
+			data.Balances = append(data.Balances, marginBalance(asset.Asset, asset.Free, asset.Locked, asset.Borrowed, asset.Interest, asset.NetAsset))
+		}
+	}
+	sort.Slice(data.Balances, func(i, j int) bool {
+		return data.Balances[i].Asset < data.Balances[j].Asset
+	})
+	u.account.Lock()
+	defer u.account.Unlock()
+	if u.account.Data != nil && data.MarginLevelStatus == "" {
+		// cross margin only learns the status from the stream
+		data.MarginLevelStatus = u.account.Data.MarginLevelStatus
+	}
+	u.account.Data = data
+	return nil
+}
+
+func (u *marginUserDataBranch) maintainUserData(
+	ctx context.Context,
+	client *Client,
+	userData *chan map[string]interface{},
+) error {
+	client = client.WithContext(ctx)
+	// get the first snapshot to initial data struct
+	if err := u.getAccountSnapShot(client); err != nil {
+		return err
+	}
+	// update snapshot with steady interval, or at once when asked
+	go func() {
+		snap := time.NewTicker(u.httpUpdateInterval.get())
+		defer snap.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-u.httpUpdateInterval.changed:
+				snap.Reset(u.httpUpdateInterval.get())
+				continue
+			case <-snap.C:
+			case <-u.snapshot:
+			}
+			if err := u.getAccountSnapShot(client); err != nil && !errors.Is(err, context.Canceled) {
+				u.insertErr(err)
+			}
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case message := <-(*userData):
+			event, ok := message["e"].(string)
+			if !ok {
+				continue
+			}
+			u.handleEvent(event, message)
+		}
+	}
+}
+
+func (u *marginUserDataBranch) handleEvent(event string, message map[string]interface{}) {
+	out := MarginUserEvent{
+		Type: event,
+	}
+	switch event {
+	case "outboundAccountPosition":
+		// the balances are in the account data
+		u.updateAccountData(message)
+		return
+	case "executionReport":
+		update, ok := parseSpotOrderUpdate(&message)
+		if !ok {
+			u.insertErr(errors.New("malformed executionReport event"))
+			return
+		}
+		out.Order = &update
+		if update.ExecutionType == "TRADE" {
+			if data, ok := parseSpotTrade(&message); ok {
+				u.insertTrade(&data)
+			}
+		}
+	case "balanceUpdate":
+		asset, ok := message["a"].(string)
+		if !ok {
+			u.insertErr(errors.New("malformed balanceUpdate event"))
+			return
+		}
+		out.Balance = &SpotBalanceUpdate{
+			Asset:     asset,
+			Delta:     mapDecimal(message, "d"),
+			ClearTime: mapTime(message, "T"),
+			EventTime: mapTime(message, "E"),
+		}
+	case "listStatus":
+		list, ok := parseOrderListUpdate(&message)
+		if !ok {
+			u.insertErr(errors.New("malformed listStatus event"))
+			return
+		}
+		out.OrderList = &list
+	case "MARGIN_LEVEL_STATUS_CHANGE":
+		out.MarginLevel = &MarginLevelUpdate{
+			MarginLevel: mapDecimal(message, "l"),
+			EventTime:   mapTime(message, "E"),
+		}
+		out.MarginLevel.Status, _ = message["s"].(string)
+		u.account.Lock()
+		if u.account.Data != nil {
+			u.account.Data.MarginLevel = out.MarginLevel.MarginLevel
+			u.account.Data.MarginLevelStatus = out.MarginLevel.Status
+		}
+		u.account.Unlock()
+	case "USER_LIABILITY_CHANGE":
+		out.Liability = &MarginLiabilityUpdate{
+			Principal:      mapDecimal(message, "p"),
+			Interest:       mapDecimal(message, "i"),
+			TotalLiability: mapDecimal(message, "l"),
+			TransactionID:  int64(mapFloat(message, "T")),
+			EventTime:      mapTime(message, "E"),
+		}
+		out.Liability.Asset, _ = message["a"].(string)
+		out.Liability.Type, _ = message["t"].(string)
+		// borrowed and interest are only in the snapshot
+		select {
+		case u.snapshot <- struct{}{}:
+		default:
+		}
+	case "listenKeyExpired":
+		// the stream reconnects by itself
+	default:
+		return
+	}
+	out.EventTime = mapTime(message, "E")
+	u.events.publish(out)
+}
+
+func (u *marginUserDataBranch) updateAccountData(message map[string]interface{}) {
+	array, ok := message["B"].([]interface{})
+	if !ok {
+		u.insertErr(errors.New("malformed outboundAccountPosition event"))
+		return
+	}
+	u.account.Lock()
+	defer u.account.Unlock()
+	if u.account.Data == nil {
+		return
+	}
+	for _, item := range array {
+		data, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		asset, ok := data["a"].(string)
+		if !ok {
+			continue
+		}
+		found := false
+		for idx, bal := range u.account.Data.Balances {
+			if bal.Asset == asset {
+				u.account.Data.Balances[idx].Free = mapDecimal(data, "f")
+				u.account.Data.Balances[idx].Locked = mapDecimal(data, "l")
+				found = true
+				break
+			}
+		}
+		if !found {
+			u.account.Data.Balances = append(u.account.Data.Balances, MarginBalance{
+				Asset:  asset,
+				Free:   mapDecimal(data, "f"),
+				Locked: mapDecimal(data, "l"),
+			})
+		}
+	}
+}
+
+func (c *Client) marginUserData(ctx context.Context, u *marginUserDataBranch, listenKey string, logger *log.Logger, mainCh *chan map[string]interface{}) error {
+	var w wS
+	var duration time.Duration = 1810
+	w.Logger = logger
+	w.OnErr = false
+	var buffer bytes.Buffer
+	innerErr := make(chan error, 1)
+	buffer.WriteString(c.endpoints.stream(u.product()))
+	buffer.WriteString(listenKey)
+	url := buffer.String()
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		return err
+	}
+	logger.Println("Connected:", url)
+	w.Conn = conn
+	defer w.Conn.Close()
+	if err := w.Conn.SetReadDeadline(time.Now().Add(time.Second * duration)); err != nil {
+		return err
+	}
+	u.link.alive()
+	w.Conn.SetPingHandler(func(appData string) error {
+		u.link.alive()
+		return w.Conn.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(time.Second))
+	})
+	go func() {
+		putKey := time.NewTicker(time.Minute * 30)
+		defer putKey.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-innerErr:
+				return
+			case <-putKey.C:
+				client := c.WithContext(ctx)
+				var err error
+				if u.isoSymbol == "" {
+					err = client.PutMarginListenKey(listenKey)
+				} else {
+					err = client.PutIsolatedMarginListenKey(u.isoSymbol, listenKey)
+				}
+				if err != nil {
+					// time out in 1 sec
+					w.Conn.SetReadDeadline(time.Now().Add(time.Second))
+					continue
+				}
+				w.Conn.SetReadDeadline(time.Now().Add(time.Second * duration))
+			}
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			w.outBinanceErr()
+			return errors.New("Binance margin private channel closed...")
+		default:
+			_, buf, err := w.Conn.ReadMessage()
+			if err != nil {
+				w.outBinanceErr()
+				innerErr <- errors.New("restart")
+				return err
+			}
+			res, err1 := decodingMap(buf, logger)
+			if err1 != nil {
+				w.outBinanceErr()
+				innerErr <- errors.New("restart")
+				return err1
+			}
+			u.link.alive()
+			select {
+			case *mainCh <- res:
+			case <-ctx.Done():
+			}
+			if event, _ := res["e"].(string); event == "listenKeyExpired" {
+				w.outBinanceErr()
+				innerErr <- errors.New("restart")
+				return errors.New("Binance margin listen key expired, reconnect...")
+			}
+			if err := w.Conn.SetReadDeadline(time.Now().Add(time.Second * duration)); err != nil {
+				innerErr <- errors.New("restart")
+				return err
+			}
+		}
+	}
+}
+
+func (u *marginUserDataBranch) insertErr(input error) {
+	if len(u.errs) == cap(u.errs) {
+		<-u.errs
+	}
+	u.errs <- input
+}
+
+func (u *marginUserDataBranch) insertTrade(input *TradeData) {
+	u.trades.Lock()
+	defer u.trades.Unlock()
+	u.trades.data = append(u.trades.data, *input)
+}
+
+func (u *marginUserDataBranch) readerrs() error {
+	var errs []string
+	for {
+		select {
+		case err := <-u.errs:
+			errs = append(errs, err.Error())
+		default:
+			if len(errs) == 0 {
+				return nil
+			}
+			return errors.New(strings.Join(errs, ", "))
+		}
+	}
+}
+
+func (i *marginIntervalBranch) get() time.Duration {
+	i.Lock()
+	defer i.Unlock()
+	return time.Second * time.Duration(i.seconds)
+}
+
+func (i *marginIntervalBranch) set(seconds int) {
+	i.Lock()
+	i.seconds = seconds
+	i.Unlock()
+	select {
+	case i.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (e *marginEventsBranch) subscribe(buffer int) <-chan MarginUserEvent {
+	ch := make(chan MarginUserEvent, buffer)
+	e.Lock()
+	defer e.Unlock()
+	if e.closed {
+		close(ch)
+		return ch
+	}
+	e.subs = append(e.subs, ch)
+	return ch
+}
+
+// a subscriber which is full has missed the event, close it so it knows
+func (e *marginEventsBranch) publish(event MarginUserEvent) {
+	e.Lock()
+	defer e.Unlock()
+	subs := e.subs[:0]
+	for _, ch := range e.subs {
+		select {
+		case ch <- event:
+			subs = append(subs, ch)
+		default:
+			close(ch)
+		}
+	}
+	e.subs = subs
+}
+
+func (e *marginEventsBranch) close() {
+	e.Lock()
+	defer e.Unlock()
+	for _, ch := range e.subs {
+		close(ch)
+	}
+	e.subs = nil
+	e.closed = true
+}
+
+func marginBalance(asset, free, locked, borrowed, interest, netAsset string) MarginBalance {
+	b := MarginBalance{
+		Asset: asset,
+	}
+	b.Free, _ = decimal.NewFromString(free)
+	b.Locked, _ = decimal.NewFromString(locked)
+	b.Borrowed, _ = decimal.NewFromString(borrowed)
+	b.Interest, _ = decimal.NewFromString(interest)
+	b.NetAsset, _ = decimal.NewFromString(netAsset)
+	return b
+}
@@ -3,6 +3,8 @@ package bnnapi
 import (
 	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 func (b *Client) PerpTransfer(method, asset string, amount float64) (*TransferResponse, error) {
@@ -172,6 +174,41 @@ type PerpPositionResponse struct {
 	PositionSide     string `json:"positionSide"`
 }
 
+type PositionModeOpts struct {
+	DualSidePosition string `url:"dualSidePosition"`
+}
+
+type PositionModeResponse struct {
+	DualSidePosition bool `json:"dualSidePosition"`
+}
+
+// true for hedge mode, false for one-way mode
+func (b *Client) PerpPositionMode() (bool, error) {
+	res, err := b.do("future", http.MethodGet, "fapi/v1/positionSide/dual", nil, true, false)
+	if err != nil {
+		return false, err
+	}
+	resp := &PositionModeResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return false, err
+	}
+	return resp.DualSidePosition, nil
+}
+
+// switch every symbol to hedge mode or back to one-way mode, the account needs to be without open orders and positions.
+// Changing to the current mode is not an error.
+func (b *Client) PerpChangePositionMode(dual bool) error {
+	opts := PositionModeOpts{
+		DualSidePosition: strconv.FormatBool(dual),
+	}
+	_, err := b.do("future", http.MethodPost, "fapi/v1/positionSide/dual", opts, true, false)
+	if err != nil && !IsErrorCode(err, ErrCodeNoNeedToChangePositionSide) {
+		return err
+	}
+	return nil
+}
+
 func (b *Client) PerpOpenInterest(symbol string) (*PerpOpenInterestResponse, error) {
 	opts := PerpOpenInterestOpts{
 		Symbol: symbol,
@@ -200,7 +237,7 @@ type PerpOpenInterestResponse struct {
 }
 
 func (b *Client) PerpNotionalandLeverage() (*[]NotionalandLeverage, error) {
-	res, err := b.do("future", http.MethodGet, "/fapi/v1/leverageBracket", nil, true, false)
+	res, err := b.do("future", http.MethodGet, "fapi/v1/leverageBracket", nil, true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -229,10 +266,10 @@ type Brackets struct {
 
 func (b *Client) PerpChangeInitialLeverage(symbol string, leverage int) (*ChangeLeverageResponse, error) {
 	opts := ChnageLeverageOpts{
-		Symbol:   symbol,
+		Symbol:   strings.ToUpper(symbol),
 		Leverage: leverage,
 	}
-	res, err := b.do("future", http.MethodPost, "/fapi/v1/leverage", opts, true, false)
+	res, err := b.do("future", http.MethodPost, "fapi/v1/leverage", opts, true, false)
 	if err != nil {
 		return nil, err
 	}
@@ -246,8 +283,8 @@ func (b *Client) PerpChangeInitialLeverage(symbol string, leverage int) (*Change
 }
 
 type ChnageLeverageOpts struct {
-	Symbol   string `json:"json"`
-	Leverage int    `json:"leverage"`
+	Symbol   string `url:"symbol"`
+	Leverage int    `url:"leverage"`
 }
 
 type ChangeLeverageResponse struct {
@@ -0,0 +1,364 @@
+package bnnapi
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+type PerpAllOrdersOpts struct {
+	Symbol string `url:"symbol"`
+	// orders from this id on
+	OrderID   int64 `url:"orderId,omitempty"`
+	StartTime int64 `url:"startTime,omitempty"`
+	EndTime   int64 `url:"endTime,omitempty"`
+	// max 1000, 500 by default
+	Limit int `url:"limit,omitempty"`
+}
+
+type PerpUserTradesOpts struct {
+	Symbol    string `url:"symbol"`
+	OrderID   int64  `url:"orderId,omitempty"`
+	StartTime int64  `url:"startTime,omitempty"`
+	EndTime   int64  `url:"endTime,omitempty"`
+	// trades from this id on, can't come with the times
+	FromID int64 `url:"fromId,omitempty"`
+	// max 1000, 500 by default
+	Limit int `url:"limit,omitempty"`
+}
+
+type PerpUserTradesResponse struct {
+	Buyer           bool   `json:"buyer"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+	ID              int64  `json:"id"`
+	Maker           bool   `json:"maker"`
+	OrderID         int64  `json:"orderId"`
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	QuoteQty        string `json:"quoteQty"`
+	RealizedPnl     string `json:"realizedPnl"`
+	Side            string `json:"side"`
+	PositionSide    string `json:"positionSide"`
+	Symbol          string `json:"symbol"`
+	Time            int64  `json:"time"`
+}
+
+type PerpForceOrdersOpts struct {
+	// empty for every symbol
+	Symbol string `url:"symbol,omitempty"`
+	// LIQUIDATION or ADL, both by default
+	AutoCloseType string `url:"autoCloseType,omitempty"`
+	StartTime     int64  `url:"startTime,omitempty"`
+	EndTime       int64  `url:"endTime,omitempty"`
+	// max 100, 50 by default
+	Limit int `url:"limit,omitempty"`
+}
+
+type PerpForceOrderResponse struct {
+	OrderID       int64  `json:"orderId"`
+	Symbol        string `json:"symbol"`
+	Status        string `json:"status"`
+	ClientOrderID string `json:"clientOrderId"`
+	Price         string `json:"price"`
+	AvgPrice      string `json:"avgPrice"`
+	OrigQty       string `json:"origQty"`
+	ExecutedQty   string `json:"executedQty"`
+	CumQuote      string `json:"cumQuote"`
+	TimeInForce   string `json:"timeInForce"`
+	Type          string `json:"type"`
+	ReduceOnly    bool   `json:"reduceOnly"`
+	ClosePosition bool   `json:"closePosition"`
+	Side          string `json:"side"`
+	PositionSide  string `json:"positionSide"`
+	StopPrice     string `json:"stopPrice"`
+	WorkingType   string `json:"workingType"`
+	OrigType      string `json:"origType"`
+	Time          int64  `json:"time"`
+	UpdateTime    int64  `json:"updateTime"`
+}
+
+type PerpADLQuantileOpts struct {
+	Symbol string `url:"symbol,omitempty"`
+}
+
+// from 0 to 4, the higher the sooner the position is auto-deleveraged
+type PerpADLQuantileResponse struct {
+	Symbol string `json:"symbol"`
+	// LONG and SHORT, HEDGE in hedge mode or BOTH in one-way mode
+	AdlQuantile map[string]int `json:"adlQuantile"`
+}
+
+// every order of the symbol, the time window can't be longer than 7 days
+func (b *Client) PerpAllOrders(opts PerpAllOrdersOpts) ([]PerpQueryOrderResonse, error) {
+	opts.Symbol = strings.ToUpper(opts.Symbol)
+	res, err := b.do("future", http.MethodGet, "fapi/v1/allOrders", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var resp []PerpQueryOrderResonse
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// the trades of the account, the time window can't be longer than 7 days
+func (b *Client) PerpUserTrades(opts PerpUserTradesOpts) ([]PerpUserTradesResponse, error) {
+	opts.Symbol = strings.ToUpper(opts.Symbol)
+	res, err := b.do("future", http.MethodGet, "fapi/v1/userTrades", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var resp []PerpUserTradesResponse
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// the liquidation and ADL orders of the account, the time window can't be longer than 7 days
+func (b *Client) PerpForceOrders(opts PerpForceOrdersOpts) ([]PerpForceOrderResponse, error) {
+	opts.Symbol = strings.ToUpper(opts.Symbol)
+	opts.AutoCloseType = strings.ToUpper(opts.AutoCloseType)
+	res, err := b.do("future", http.MethodGet, "fapi/v1/forceOrders", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var resp []PerpForceOrderResponse
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// empty symbol for every position, updated by the exchange every 60 sec
+func (b *Client) PerpADLQuantile(symbol string) ([]PerpADLQuantileResponse, error) {
+	opts := PerpADLQuantileOpts{
+		Symbol: strings.ToUpper(symbol),
+	}
+	res, err := b.do("future", http.MethodGet, "fapi/v1/adlQuantile", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var resp []PerpADLQuantileResponse
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PerpOrderIterator walks the order history page by page, used the same way as SpotOrderIterator
+type PerpOrderIterator struct {
+	client *Client
+	symbol string
+	cursor *historyCursor
+	buf    []PerpQueryOrderResonse
+	cur    PerpQueryOrderResonse
+	err    error
+}
+
+// walk by order id from fromOrderID when it's set, otherwise by 7 day windows from start to end.
+// Zero end is now, start is needed without fromOrderID.
+func (b *Client) PerpOrderHistory(symbol string, fromOrderID int64, start, end time.Time) *PerpOrderIterator {
+	it := &PerpOrderIterator{
+		client: b,
+		symbol: strings.ToUpper(symbol),
+	}
+	if fromOrderID != 0 {
+		it.cursor = newHistoryCursorByID(fromOrderID, start, end, 1000)
+	} else {
+		it.cursor = newHistoryCursor(start, end, perpHistoryWindow, 1000)
+		if start.IsZero() {
+			it.err = errHistoryStart
+		}
+	}
+	return it
+}
+
+func (it *PerpOrderIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.err != nil {
+			return false
+		}
+		page, ok := it.cursor.page()
+		if !ok {
+			return false
+		}
+		orders, err := it.client.PerpAllOrders(PerpAllOrdersOpts{
+			Symbol:    it.symbol,
+			OrderID:   page.FromID,
+			StartTime: page.StartTime,
+			EndTime:   page.EndTime,
+			Limit:     it.cursor.limit,
+		})
+		if err != nil {
+			it.err = err
+			return false
+		}
+		keys := make([]historyKey, len(orders))
+		for i, order := range orders {
+			keys[i] = historyKey{ID: int64(order.OrderID), Time: order.Time}
+		}
+		for i, keep := range it.cursor.advance(keys) {
+			if keep {
+				it.buf = append(it.buf, orders[i])
+			}
+		}
+	}
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+func (it *PerpOrderIterator) Order() PerpQueryOrderResonse {
+	return it.cur
+}
+
+// the error which stopped the walk, nil when it's done
+func (it *PerpOrderIterator) Err() error {
+	return it.err
+}
+
+// PerpTradeIterator walks the trade history page by page, used the same way as SpotOrderIterator
+type PerpTradeIterator struct {
+	client *Client
+	symbol string
+	cursor *historyCursor
+	buf    []PerpUserTradesResponse
+	cur    PerpUserTradesResponse
+	err    error
+}
+
+// walk by trade id from fromID when it's set, otherwise by 7 day windows from start to end.
+// Zero end is now, start is needed without fromID.
+func (b *Client) PerpTradeHistory(symbol string, fromID int64, start, end time.Time) *PerpTradeIterator {
+	it := &PerpTradeIterator{
+		client: b,
+		symbol: strings.ToUpper(symbol),
+	}
+	if fromID != 0 {
+		it.cursor = newHistoryCursorByID(fromID, start, end, 1000)
+	} else {
+		it.cursor = newHistoryCursor(start, end, perpHistoryWindow, 1000)
+		if start.IsZero() {
+			it.err = errHistoryStart
+		}
+	}
+	return it
+}
+
+func (it *PerpTradeIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.err != nil {
+			return false
+		}
+		page, ok := it.cursor.page()
+		if !ok {
+			return false
+		}
+		trades, err := it.client.PerpUserTrades(PerpUserTradesOpts{
+			Symbol:    it.symbol,
+			FromID:    page.FromID,
+			StartTime: page.StartTime,
+			EndTime:   page.EndTime,
+			Limit:     it.cursor.limit,
+		})
+		if err != nil {
+			it.err = err
+			return false
+		}
+		keys := make([]historyKey, len(trades))
+		for i, trade := range trades {
+			keys[i] = historyKey{ID: trade.ID, Time: trade.Time}
+		}
+		for i, keep := range it.cursor.advance(keys) {
+			if keep {
+				it.buf = append(it.buf, trades[i])
+			}
+		}
+	}
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+func (it *PerpTradeIterator) Trade() PerpUserTradesResponse {
+	return it.cur
+}
+
+// the error which stopped the walk, nil when it's done
+func (it *PerpTradeIterator) Err() error {
+	return it.err
+}
+
+// PerpForceOrderIterator walks the liquidation and ADL orders, used the same way as SpotOrderIterator
+type PerpForceOrderIterator struct {
+	client        *Client
+	symbol        string
+	autoCloseType string
+	cursor        *historyCursor
+	buf           []PerpForceOrderResponse
+	cur           PerpForceOrderResponse
+	err           error
+}
+
+// walk by 7 day windows from start to end, empty symbol for every symbol and empty autoCloseType for both types.
+// Zero end is now.
+func (b *Client) PerpForceOrderHistory(symbol, autoCloseType string, start, end time.Time) *PerpForceOrderIterator {
+	it := &PerpForceOrderIterator{
+		client:        b,
+		symbol:        strings.ToUpper(symbol),
+		autoCloseType: strings.ToUpper(autoCloseType),
+		cursor:        newHistoryCursor(start, end, perpHistoryWindow, 100),
+	}
+	if start.IsZero() {
+		it.err = errHistoryStart
+	}
+	return it
+}
+
+func (it *PerpForceOrderIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.err != nil {
+			return false
+		}
+		page, ok := it.cursor.page()
+		if !ok {
+			return false
+		}
+		orders, err := it.client.PerpForceOrders(PerpForceOrdersOpts{
+			Symbol:        it.symbol,
+			AutoCloseType: it.autoCloseType,
+			StartTime:     page.StartTime,
+			EndTime:       page.EndTime,
+			Limit:         it.cursor.limit,
+		})
+		if err != nil {
+			it.err = err
+			return false
+		}
+		keys := make([]historyKey, len(orders))
+		for i, order := range orders {
+			keys[i] = historyKey{ID: order.OrderID, Time: order.Time}
+		}
+		for i, keep := range it.cursor.advance(keys) {
+			if keep {
+				it.buf = append(it.buf, orders[i])
+			}
+		}
+	}
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+func (it *PerpForceOrderIterator) Order() PerpForceOrderResponse {
+	return it.cur
+}
+
+// the error which stopped the walk, nil when it's done
+func (it *PerpForceOrderIterator) Err() error {
+	return it.err
+}
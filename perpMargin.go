@@ -0,0 +1,137 @@
+package bnnapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type MarginTypeOpts struct {
+	Symbol string `url:"symbol"`
+	// ISOLATED or CROSSED
+	MarginType string `url:"marginType"`
+}
+
+type PositionMarginOpts struct {
+	Symbol string `url:"symbol"`
+	// BOTH in one-way mode, LONG or SHORT in hedge mode
+	PositionSide string `url:"positionSide,omitempty"`
+	Amount       string `url:"amount"`
+	// 1 to add, 2 to reduce
+	Type int `url:"type"`
+}
+
+type PositionMarginResponse struct {
+	Amount float64 `json:"amount"`
+	Code   int     `json:"code"`
+	Msg    string  `json:"msg"`
+	Type   int     `json:"type"`
+}
+
+type PositionMarginHistoryOpts struct {
+	Symbol    string `url:"symbol"`
+	Type      int    `url:"type,omitempty"`
+	StartTime int64  `url:"startTime,omitempty"`
+	EndTime   int64  `url:"endTime,omitempty"`
+	Limit     int    `url:"limit,omitempty"`
+}
+
+type PositionMarginHistoryResponse struct {
+	Symbol       string `json:"symbol"`
+	Type         int    `json:"type"`
+	DeltaType    string `json:"deltaType"`
+	Amount       string `json:"amount"`
+	Asset        string `json:"asset"`
+	Time         int64  `json:"time"`
+	PositionSide string `json:"positionSide"`
+}
+
+// ISOLATED or CROSSED, changing to the current type is not an error.
+// The exchange rejects the change while the symbol has open orders or a position.
+func (b *Client) PerpChangeMarginType(symbol, marginType string) error {
+	opts := MarginTypeOpts{
+		Symbol:     strings.ToUpper(symbol),
+		MarginType: strings.ToUpper(marginType),
+	}
+	switch opts.MarginType {
+	case "ISOLATED", "CROSSED":
+	default:
+		return fmt.Errorf("unknown marginType %q", marginType)
+	}
+	_, err := b.do("future", http.MethodPost, "fapi/v1/marginType", opts, true, false)
+	if err != nil && !IsErrorCode(err, ErrCodeNoNeedToChangeMarginType) {
+		return err
+	}
+	return nil
+}
+
+// add margin to an isolated position, positionSide is needed in hedge mode only
+func (b *Client) PerpAddPositionMargin(symbol, positionSide, amount string) (*PositionMarginResponse, error) {
+	return b.perpModifyPositionMargin(symbol, positionSide, amount, 1)
+}
+
+// take margin out of an isolated position, positionSide is needed in hedge mode only
+func (b *Client) PerpReducePositionMargin(symbol, positionSide, amount string) (*PositionMarginResponse, error) {
+	return b.perpModifyPositionMargin(symbol, positionSide, amount, 2)
+}
+
+// marginType 1 for additions, 2 for reductions and 0 for both, zero times and limit are not sent
+func (b *Client) PerpPositionMarginHistory(symbol string, marginType int, start, end time.Time, limit int) ([]PositionMarginHistoryResponse, error) {
+	opts := PositionMarginHistoryOpts{
+		Symbol: strings.ToUpper(symbol),
+		Type:   marginType,
+		Limit:  limit,
+	}
+	if !start.IsZero() {
+		opts.StartTime = start.UnixMilli()
+	}
+	if !end.IsZero() {
+		opts.EndTime = end.UnixMilli()
+	}
+	res, err := b.do("future", http.MethodGet, "fapi/v1/positionMargin/history", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var resp []PositionMarginHistoryResponse
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// get a symbol ready before trading, the margin type goes first since it can't be changed with a position.
+// Settings which are already in place count as success.
+func (b *Client) PerpSetupSymbol(symbol string, leverage int, marginType string) error {
+	if err := b.PerpChangeMarginType(symbol, marginType); err != nil {
+		return err
+	}
+	_, err := b.PerpChangeInitialLeverage(symbol, leverage)
+	return err
+}
+
+// internal funcs ------------------------------------------------
+
+func (b *Client) perpModifyPositionMargin(symbol, positionSide, amount string, marginType int) (*PositionMarginResponse, error) {
+	opts := PositionMarginOpts{
+		Symbol:       strings.ToUpper(symbol),
+		PositionSide: strings.ToUpper(positionSide),
+		Amount:       amount,
+		Type:         marginType,
+	}
+	if amount == "" {
+		return nil, errors.New("amount is missing")
+	}
+	res, err := b.do("future", http.MethodPost, "fapi/v1/positionMargin", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := &PositionMarginResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,181 @@
+package bnnapi
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+// modify the price and qty of an open LIMIT order, set OrderID or OrigClientOrderID
+type PerpModifyOrderRequest struct {
+	Symbol            string `url:"symbol"`
+	OrderID           int64  `url:"orderId,omitempty"`
+	OrigClientOrderID string `url:"origClientOrderId,omitempty"`
+	// has to be the side of the order
+	Side  string `url:"side"`
+	Qty   string `url:"quantity"`
+	Price string `url:"price,omitempty"`
+	// instead of price, see PerpOrderRequest
+	PriceMatch string `url:"priceMatch,omitempty"`
+}
+
+type PerpOrderAmendmentOpts struct {
+	Symbol            string `url:"symbol"`
+	OrderID           int64  `url:"orderId,omitempty"`
+	OrigClientOrderID string `url:"origClientOrderId,omitempty"`
+	StartTime         int64  `url:"startTime,omitempty"`
+	EndTime           int64  `url:"endTime,omitempty"`
+	Limit             int    `url:"limit,omitempty"`
+}
+
+type AmendmentChange struct {
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+type PerpOrderAmendmentResponse struct {
+	AmendmentID   int64  `json:"amendmentId"`
+	Symbol        string `json:"symbol"`
+	Pair          string `json:"pair"`
+	OrderID       int64  `json:"orderId"`
+	ClientOrderID string `json:"clientOrderId"`
+	Time          int64  `json:"time"`
+	Amendment     struct {
+		Price   AmendmentChange `json:"price"`
+		OrigQty AmendmentChange `json:"origQty"`
+		// times the order has been modified
+		Count int `json:"count"`
+	} `json:"amendment"`
+}
+
+// the order keeps its id, the exchange cancels it when the new qty is not above the executed qty
+func (b *Client) PerpModifyOrder(req PerpModifyOrderRequest) (*PerpOrderResponse, error) {
+	req.normalize()
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := b.checkOrder("future", req.Symbol, orderFields{side: req.Side, orderType: "LIMIT", price: &req.Price, qty: &req.Qty}); err != nil {
+		return nil, err
+	}
+	if b.dryRun.enabled {
+		return nil, ErrDryRunUnsupported
+	}
+	res, err := b.do("future", http.MethodPut, "fapi/v1/order", req, true, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := &PerpOrderResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// max 5 orders per request, every order is checked before sending any of them.
+// The orders are modified independently, use Failed on the response to find the rejected ones.
+func (b *Client) PerpModifyBatchOrders(reqs []PerpModifyOrderRequest) (*PerpBatchOrdersResponse, error) {
+	if len(reqs) == 0 || len(reqs) > 5 {
+		return nil, invalidOrder("batch takes 1 to 5 orders, got %d", len(reqs))
+	}
+	orders := make([]map[string]string, 0, len(reqs))
+	for i := range reqs {
+		req := &reqs[i]
+		req.normalize()
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+		if err := b.checkOrder("future", req.Symbol, orderFields{side: req.Side, orderType: "LIMIT", price: &req.Price, qty: &req.Qty}); err != nil {
+			return nil, err
+		}
+		values, err := query.Values(req)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]string, len(values))
+		for key := range values {
+			m[key] = values.Get(key)
+		}
+		orders = append(orders, m)
+	}
+	if b.dryRun.enabled {
+		return nil, ErrDryRunUnsupported
+	}
+	out, err := json.Marshal(orders)
+	if err != nil {
+		return nil, err
+	}
+	input := PlaceBatchOrdersOptsPerp{}
+	input.OrderList = Bytes2String(out)
+	res, err := b.do("future", http.MethodPut, "fapi/v1/batchOrders", input, true, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := PerpBatchOrdersResponse{}
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// check the request on our side before sending
+func (r *PerpModifyOrderRequest) Validate() error {
+	if r.Symbol == "" {
+		return invalidOrder("symbol is missing")
+	}
+	if r.OrderID == 0 && r.OrigClientOrderID == "" {
+		return invalidOrder("orderId or origClientOrderId is needed")
+	}
+	if r.Side != "BUY" && r.Side != "SELL" {
+		return invalidOrder("unknown side %q", r.Side)
+	}
+	if r.Qty == "" {
+		return invalidOrder("quantity is missing")
+	}
+	if (r.Price == "") == (r.PriceMatch == "") {
+		return invalidOrder("either price or priceMatch is needed")
+	}
+	switch r.PriceMatch {
+	case "", "OPPONENT", "OPPONENT_5", "OPPONENT_10", "OPPONENT_20", "QUEUE", "QUEUE_5", "QUEUE_10", "QUEUE_20":
+	default:
+		return invalidOrder("unknown priceMatch %q", r.PriceMatch)
+	}
+	return nil
+}
+
+// set orderID or origClientOrderID, or neither for every order of the symbol. Zero times and limit are not sent.
+func (b *Client) PerpOrderAmendments(symbol string, orderID int64, origClientOrderID string, start, end time.Time, limit int) ([]PerpOrderAmendmentResponse, error) {
+	opts := PerpOrderAmendmentOpts{
+		Symbol:            strings.ToUpper(symbol),
+		OrderID:           orderID,
+		OrigClientOrderID: origClientOrderID,
+		Limit:             limit,
+	}
+	if !start.IsZero() {
+		opts.StartTime = start.UnixMilli()
+	}
+	if !end.IsZero() {
+		opts.EndTime = end.UnixMilli()
+	}
+	res, err := b.do("future", http.MethodGet, "fapi/v1/orderAmendment", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var resp []PerpOrderAmendmentResponse
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// internal funcs ------------------------------------------------
+
+func (r *PerpModifyOrderRequest) normalize() {
+	r.Symbol = strings.ToUpper(r.Symbol)
+	r.Side = strings.ToUpper(r.Side)
+	r.PriceMatch = strings.ToUpper(r.PriceMatch)
+}
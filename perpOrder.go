@@ -0,0 +1,276 @@
+package bnnapi
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/go-querystring/query"
+	"github.com/shopspring/decimal"
+)
+
+// PerpOrderRequest covers every parameter of fapi/v1/order, empty fields are not sent
+type PerpOrderRequest struct {
+	Symbol string `url:"symbol"`
+	Side   string `url:"side"`
+	// BOTH in one-way mode, LONG or SHORT in hedge mode
+	PositionSide string `url:"positionSide,omitempty"`
+	// LIMIT, MARKET, STOP, TAKE_PROFIT, STOP_MARKET, TAKE_PROFIT_MARKET or TRAILING_STOP_MARKET
+	Type        string `url:"type"`
+	TimeInForce string `url:"timeInForce,omitempty"`
+	Qty         string `url:"quantity,omitempty"`
+	// not in hedge mode
+	ReduceOnly bool   `url:"reduceOnly,omitempty"`
+	Price      string `url:"price,omitempty"`
+	ClientID   string `url:"newClientOrderId,omitempty"`
+	StopPrice  string `url:"stopPrice,omitempty"`
+	// close the whole position, STOP_MARKET and TAKE_PROFIT_MARKET only
+	ClosePosition bool `url:"closePosition,omitempty"`
+	// TRAILING_STOP_MARKET only, the latest price by default
+	ActivationPrice string `url:"activationPrice,omitempty"`
+	// TRAILING_STOP_MARKET only, in percent from 0.1 to 10
+	CallbackRate string `url:"callbackRate,omitempty"`
+	// MARK_PRICE or CONTRACT_PRICE, which price triggers the stop
+	WorkingType  string `url:"workingType,omitempty"`
+	PriceProtect bool   `url:"priceProtect,omitempty"`
+	// ACK or RESULT
+	NewOrderRespType string `url:"newOrderRespType,omitempty"`
+	// OPPONENT, OPPONENT_5, OPPONENT_10, OPPONENT_20, QUEUE, QUEUE_5, QUEUE_10 or QUEUE_20, instead of price
+	PriceMatch string `url:"priceMatch,omitempty"`
+	// EXPIRE_TAKER, EXPIRE_MAKER, EXPIRE_BOTH or NONE
+	SelfTradePreventionMode string `url:"selfTradePreventionMode,omitempty"`
+	// in milliseconds, GTD only
+	GoodTillDate int64 `url:"goodTillDate,omitempty"`
+}
+
+// place any kind of perp order, the request is checked on our side first.
+// TimeInForce defaults to GTC for LIMIT, STOP and TAKE_PROFIT.
+func (b *Client) PerpPlaceOrderRequest(req PerpOrderRequest) (*PerpOrderResponse, error) {
+	req.normalize()
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := b.checkOrder("future", req.Symbol, req.checkFields()); err != nil {
+		return nil, err
+	}
+	if b.dryRun.enabled {
+		return dryRunPerpOrder(req), nil
+	}
+	req.ClientID = b.orderClientID(req.ClientID)
+	res, err := b.placeOrderOnce("future", "fapi/v1/order", req, req.ClientID, b.perpQueryByClientID(req.Symbol, req.ClientID))
+	if err != nil {
+		return nil, err
+	}
+	resp := &PerpOrderResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// max 5 orders per request, every order is checked before sending any of them.
+// The orders are placed independently, a failed one has Code and Msg set in its slot of the response.
+func (b *Client) PerpPlaceBatchOrderRequests(reqs []PerpOrderRequest) (*PerpBatchOrdersResponse, error) {
+	if len(reqs) == 0 || len(reqs) > 5 {
+		return nil, invalidOrder("batch takes 1 to 5 orders, got %d", len(reqs))
+	}
+	orders := make([]map[string]string, 0, len(reqs))
+	for i := range reqs {
+		req := &reqs[i]
+		req.normalize()
+		if err := req.Validate(); err != nil {
+			return nil, err
+		}
+		if err := b.checkOrder("future", req.Symbol, req.checkFields()); err != nil {
+			return nil, err
+		}
+		req.ClientID = b.orderClientID(req.ClientID)
+		values, err := query.Values(req)
+		if err != nil {
+			return nil, err
+		}
+		m := make(map[string]string, len(values))
+		for key := range values {
+			m[key] = values.Get(key)
+		}
+		orders = append(orders, m)
+	}
+	if b.dryRun.enabled {
+		resp := dryRunPerpBatch(reqs)
+		return &resp, nil
+	}
+	out, err := json.Marshal(orders)
+	if err != nil {
+		return nil, err
+	}
+	input := PlaceBatchOrdersOptsPerp{}
+	input.OrderList = Bytes2String(out)
+	res, err := b.do("future", http.MethodPost, "fapi/v1/batchOrders", input, true, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := PerpBatchOrdersResponse{}
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// check the combination of the parameters for the order type
+func (r *PerpOrderRequest) Validate() error {
+	if r.Symbol == "" {
+		return invalidOrder("symbol is missing")
+	}
+	if r.Side != "BUY" && r.Side != "SELL" {
+		return invalidOrder("unknown side %q", r.Side)
+	}
+	switch r.PositionSide {
+	case "", "BOTH":
+	case "LONG", "SHORT":
+		if r.ReduceOnly {
+			return invalidOrder("reduceOnly can't be sent in hedge mode")
+		}
+	default:
+		return invalidOrder("unknown positionSide %q", r.PositionSide)
+	}
+	var needPrice, needStop, needTIF, isStop, isTrailing bool
+	switch r.Type {
+	case "LIMIT":
+		needPrice, needTIF = true, true
+	case "MARKET":
+	case "STOP", "TAKE_PROFIT":
+		needPrice, needStop, needTIF, isStop = true, true, true, true
+	case "STOP_MARKET", "TAKE_PROFIT_MARKET":
+		needStop, isStop = true, true
+	case "TRAILING_STOP_MARKET":
+		isStop, isTrailing = true, true
+	default:
+		return invalidOrder("unknown order type %q", r.Type)
+	}
+	if r.ClosePosition {
+		if r.Type != "STOP_MARKET" && r.Type != "TAKE_PROFIT_MARKET" {
+			return invalidOrder("closePosition is only for STOP_MARKET and TAKE_PROFIT_MARKET")
+		}
+		if r.Qty != "" || r.ReduceOnly {
+			return invalidOrder("closePosition can't come with quantity or reduceOnly")
+		}
+	} else if r.Qty == "" {
+		return invalidOrder("%s needs quantity", r.Type)
+	}
+	if r.PriceMatch != "" {
+		if !needPrice {
+			return invalidOrder("%s doesn't take priceMatch", r.Type)
+		}
+		if r.Price != "" {
+			return invalidOrder("priceMatch can't come with price")
+		}
+		switch r.PriceMatch {
+		case "OPPONENT", "OPPONENT_5", "OPPONENT_10", "OPPONENT_20", "QUEUE", "QUEUE_5", "QUEUE_10", "QUEUE_20":
+		default:
+			return invalidOrder("unknown priceMatch %q", r.PriceMatch)
+		}
+	} else if needPrice && r.Price == "" {
+		return invalidOrder("%s needs price", r.Type)
+	}
+	if !needPrice && r.Price != "" {
+		return invalidOrder("%s doesn't take price", r.Type)
+	}
+	if needStop && r.StopPrice == "" {
+		return invalidOrder("%s needs stopPrice", r.Type)
+	}
+	if !needStop && r.StopPrice != "" {
+		return invalidOrder("%s doesn't take stopPrice", r.Type)
+	}
+	if isTrailing {
+		rate, err := decimal.NewFromString(r.CallbackRate)
+		if err != nil {
+			return invalidOrder("TRAILING_STOP_MARKET needs callbackRate")
+		}
+		if rate.LessThan(decimal.NewFromFloat(0.1)) || rate.GreaterThan(decimal.NewFromInt(10)) {
+			return invalidOrder("callbackRate %s out of 0.1 to 10", r.CallbackRate)
+		}
+	} else if r.CallbackRate != "" || r.ActivationPrice != "" {
+		return invalidOrder("%s doesn't take callbackRate or activationPrice", r.Type)
+	}
+	if !isStop && (r.WorkingType != "" || r.PriceProtect) {
+		return invalidOrder("%s doesn't take workingType or priceProtect", r.Type)
+	}
+	switch r.WorkingType {
+	case "", "MARK_PRICE", "CONTRACT_PRICE":
+	default:
+		return invalidOrder("unknown workingType %q", r.WorkingType)
+	}
+	switch r.TimeInForce {
+	case "":
+		if needTIF {
+			return invalidOrder("%s needs timeInForce", r.Type)
+		}
+	case "GTC", "IOC", "FOK", "GTX", "GTD":
+		if !needTIF {
+			return invalidOrder("%s doesn't take timeInForce", r.Type)
+		}
+	default:
+		return invalidOrder("unknown timeInForce %q", r.TimeInForce)
+	}
+	if (r.TimeInForce == "GTD") != (r.GoodTillDate != 0) {
+		return invalidOrder("goodTillDate goes with timeInForce GTD")
+	}
+	switch r.NewOrderRespType {
+	case "", "ACK", "RESULT":
+	default:
+		return invalidOrder("unknown newOrderRespType %q", r.NewOrderRespType)
+	}
+	switch r.SelfTradePreventionMode {
+	case "", "EXPIRE_TAKER", "EXPIRE_MAKER", "EXPIRE_BOTH", "NONE":
+	default:
+		return invalidOrder("unknown selfTradePreventionMode %q", r.SelfTradePreventionMode)
+	}
+	return nil
+}
+
+// internal funcs ------------------------------------------------
+
+func (r *PerpOrderRequest) normalize() {
+	r.Symbol = strings.ToUpper(r.Symbol)
+	r.Side = strings.ToUpper(r.Side)
+	r.PositionSide = strings.ToUpper(r.PositionSide)
+	r.Type = strings.ToUpper(r.Type)
+	r.TimeInForce = strings.ToUpper(r.TimeInForce)
+	r.WorkingType = strings.ToUpper(r.WorkingType)
+	r.NewOrderRespType = strings.ToUpper(r.NewOrderRespType)
+	r.PriceMatch = strings.ToUpper(r.PriceMatch)
+	r.SelfTradePreventionMode = strings.ToUpper(r.SelfTradePreventionMode)
+	if r.TimeInForce == "" {
+		switch r.Type {
+		case "LIMIT", "STOP", "TAKE_PROFIT":
+			r.TimeInForce = "GTC"
+		}
+	}
+}
+
+func (r *PerpOrderRequest) checkFields() orderFields {
+	return orderFields{
+		side:            r.Side,
+		orderType:       r.Type,
+		price:           &r.Price,
+		qty:             &r.Qty,
+		stopPrice:       &r.StopPrice,
+		activationPrice: &r.ActivationPrice,
+	}
+}
+
+// the old opts with reduceOnly as "true" or "false"
+func (o PlaceOrderOptsPerp) request() PerpOrderRequest {
+	return PerpOrderRequest{
+		Symbol:       o.Symbol,
+		Side:         o.Side,
+		Type:         o.Type,
+		TimeInForce:  o.TimeInForce,
+		Price:        o.Price,
+		Qty:          o.Qty,
+		ReduceOnly:   strings.ToLower(o.ReduceOnly) == "true",
+		ClientID:     o.ClientID,
+		PositionSide: o.PositionSide,
+	}
+}
@@ -13,6 +13,9 @@ type PlaceOrderOptsPerp struct {
 	Type        string `url:"type"`
 	Side        string `url:"side"`
 	ReduceOnly  string `url:"reduceOnly"`
+	ClientID    string `url:"newClientOrderId,omitempty"`
+	// LONG or SHORT in hedge mode
+	PositionSide string `url:"positionSide,omitempty"`
 }
 
 type PlaceOrderOptsPerpMarket struct {
@@ -21,63 +24,34 @@ type PlaceOrderOptsPerpMarket struct {
 	Type       string `url:"type"`
 	Side       string `url:"side"`
 	ReduceOnly string `url:"reduceOnly"`
-	ClientID   string `url:"newClientOrderId, omitempty"`
+	ClientID   string `url:"newClientOrderId,omitempty"`
+	// LONG or SHORT in hedge mode
+	PositionSide string `url:"positionSide,omitempty"`
 }
 
+// one-way mode only, use PerpPlaceOrderRequest with PositionSide in hedge mode
 func (b *Client) PerpPlaceOrderMarket(symbol, side string, size string, reduceOnly, clientID string) (*PerpOrderResponse, error) {
-	usymbol := strings.ToUpper(symbol)
-	uside := strings.ToUpper(side)
-	opts := PlaceOrderOptsPerpMarket{
-		Symbol:     usymbol,
-		Side:       uside,
-		Qty:        size,
+	return b.PerpPlaceOrderRequest(PerpOrderRequest{
+		Symbol:     symbol,
+		Side:       side,
 		Type:       "MARKET",
-		ReduceOnly: reduceOnly,
-	}
-	if clientID != "" {
-		opts.ClientID = clientID
-	}
-	res, err := b.do("future", http.MethodPost, "fapi/v1/order", opts, true, false)
-	if err != nil {
-		return nil, err
-	}
-	resp := &PerpOrderResponse{}
-	err = json.Unmarshal(res, resp)
-	if err != nil {
-		return nil, err
-	}
-	return resp, nil
+		Qty:        size,
+		ReduceOnly: strings.ToLower(reduceOnly) == "true",
+		ClientID:   clientID,
+	})
 }
 
+// one-way mode only, use PerpPlaceOrderRequest with PositionSide in hedge mode
 func (b *Client) PerpPlaceOrder(symbol, side string, price, size string, orderType, timeInforce, reduceOnly string) (*PerpOrderResponse, error) {
-	usymbol := strings.ToUpper(symbol)
-	uside := strings.ToUpper(side)
-	utype := strings.ToUpper(orderType)
-	var utif string
-	if timeInforce == "" {
-		utif = "GTC"
-	} else {
-		utif = strings.ToUpper(timeInforce)
-	}
-	opts := PlaceOrderOptsPerp{
-		Symbol:      usymbol,
-		Side:        uside,
+	return b.PerpPlaceOrderRequest(PerpOrderRequest{
+		Symbol:      symbol,
+		Side:        side,
+		Type:        orderType,
 		Price:       price,
 		Qty:         size,
-		Type:        utype,
-		TimeInForce: utif,
-		ReduceOnly:  reduceOnly,
-	}
-	res, err := b.do("future", http.MethodPost, "fapi/v1/order", opts, true, false)
-	if err != nil {
-		return nil, err
-	}
-	resp := &PerpOrderResponse{}
-	err = json.Unmarshal(res, resp)
-	if err != nil {
-		return nil, err
-	}
-	return resp, nil
+		TimeInForce: timeInforce,
+		ReduceOnly:  strings.ToLower(reduceOnly) == "true",
+	})
 }
 
 type PlaceBatchOrdersOptsPerp struct {
@@ -86,59 +60,40 @@ type PlaceBatchOrdersOptsPerp struct {
 
 // max 5 orders per request
 func (b *Client) PerpPlaceBatchOrders(orders []PlaceOrderOptsPerp) (*PerpBatchOrdersResponse, error) {
-	opts := []map[string]interface{}{}
+	reqs := make([]PerpOrderRequest, 0, len(orders))
 	for _, order := range orders {
-		m := map[string]interface{}{}
-		m["symbol"] = strings.ToUpper(order.Symbol)
-		m["side"] = strings.ToUpper(order.Side)
-		m["type"] = strings.ToUpper(order.Type)
-		m["timeInForce"] = strings.ToUpper(order.TimeInForce)
-		m["price"] = order.Price
-		m["quantity"] = order.Qty
-		m["reduceOnly"] = strings.ToLower(order.ReduceOnly)
-		opts = append(opts, m)
-	}
-	out, err := json.Marshal(opts)
-	if err != nil {
-		return nil, err
-	}
-	input := PlaceBatchOrdersOptsPerp{}
-	input.OrderList = Bytes2String(out)
-	res, err := b.do("future", http.MethodPost, "fapi/v1/batchOrders", input, true, false)
-	if err != nil {
-		return nil, err
-	}
-	resp := PerpBatchOrdersResponse{}
-	err = json.Unmarshal(res, &resp)
-	if err != nil {
-		return nil, err
+		reqs = append(reqs, order.request())
 	}
-	return &resp, nil
+	return b.PerpPlaceBatchOrderRequests(reqs)
 }
 
 type PerpOrderResponse struct {
-	ClientOrderID string `json:"clientOrderId"`
-	CumQty        string `json:"cumQty"`
-	CumQuote      string `json:"cumQuote"`
-	ExecutedQty   string `json:"executedQty"`
-	OrderID       int    `json:"orderId"`
-	AvgPrice      string `json:"avgPrice, omitempty"`
-	OrigQty       string `json:"origQty"`
-	Price         string `json:"price"`
-	ReduceOnly    bool   `json:"reduceOnly"`
-	Side          string `json:"side"`
-	PositionSide  string `json:"positionSide"`
-	Status        string `json:"status"`
-	StopPrice     string `json:"stopPrice, omitempty"`
-	ClosePosition bool   `json:"closePosition, omitempty"`
-	Symbol        string `json:"symbol"`
-	TimeInForce   string `json:"timeInForce"`
-	Type          string `json:"type"`
-	OrigType      string `json:"origType"`
-	ActivatePrice string `json:"activatePrice, omitempty"`
-	PriceRate     string `json:"priceRate, omitempty"`
-	UpdateTime    int64  `json:"updateTime"`
-	WorkingType   string `json:"workingType"`
+	ClientOrderID           string `json:"clientOrderId"`
+	CumQty                  string `json:"cumQty"`
+	CumQuote                string `json:"cumQuote"`
+	ExecutedQty             string `json:"executedQty"`
+	OrderID                 int    `json:"orderId"`
+	AvgPrice                string `json:"avgPrice, omitempty"`
+	OrigQty                 string `json:"origQty"`
+	Price                   string `json:"price"`
+	ReduceOnly              bool   `json:"reduceOnly"`
+	Side                    string `json:"side"`
+	PositionSide            string `json:"positionSide"`
+	Status                  string `json:"status"`
+	StopPrice               string `json:"stopPrice, omitempty"`
+	ClosePosition           bool   `json:"closePosition, omitempty"`
+	Symbol                  string `json:"symbol"`
+	TimeInForce             string `json:"timeInForce"`
+	Type                    string `json:"type"`
+	OrigType                string `json:"origType"`
+	ActivatePrice           string `json:"activatePrice, omitempty"`
+	PriceRate               string `json:"priceRate, omitempty"`
+	UpdateTime              int64  `json:"updateTime"`
+	WorkingType             string `json:"workingType"`
+	PriceProtect            bool   `json:"priceProtect"`
+	PriceMatch              string `json:"priceMatch"`
+	SelfTradePreventionMode string `json:"selfTradePreventionMode"`
+	GoodTillDate            int64  `json:"goodTillDate"`
 }
 
 type PerpBatchOrdersResponse []struct {
@@ -164,10 +119,32 @@ type PerpBatchOrdersResponse []struct {
 	Updatetime    int64  `json:"updateTime,omitempty"`
 	Workingtype   string `json:"workingType,omitempty"`
 	Priceprotect  bool   `json:"priceProtect,omitempty"`
+	Closeposition bool   `json:"closePosition,omitempty"`
+	Pricematch    string `json:"priceMatch,omitempty"`
+	Goodtilldate  int64  `json:"goodTillDate,omitempty"`
 	Code          int    `json:"code,omitempty"`
 	Msg           string `json:"msg,omitempty"`
 }
 
+// the slots which failed on the exchange by index, nil when every order went through
+func (r PerpBatchOrdersResponse) Failed() map[int]*APIError {
+	var failed map[int]*APIError
+	for i, order := range r {
+		if order.Code == 0 {
+			continue
+		}
+		if failed == nil {
+			failed = make(map[int]*APIError)
+		}
+		failed[i] = &APIError{
+			StatusCode: http.StatusOK,
+			Code:       order.Code,
+			Message:    order.Msg,
+		}
+	}
+	return failed
+}
+
 func (b *Client) PerpCancelOrder(symbol string, oid int) (*PerpOrderResponse, error) {
 	usymbol := strings.ToUpper(symbol)
 	opts := OIDOpts{
@@ -257,6 +234,34 @@ type PerpQueryOrderResonse struct {
 	PriceProtect  bool   `json:"priceProtect"`
 }
 
+type ClientOIDOpts struct {
+	Symbol   string `url:"symbol"`
+	ClientID string `url:"origClientOrderId"`
+}
+
+func (b *Client) PerpQueryOrderByClientID(symbol, clientID string) (*PerpQueryOrderResonse, error) {
+	res, err := b.perpQueryByClientID(strings.ToUpper(symbol), clientID)()
+	if err != nil {
+		return nil, err
+	}
+	resp := &PerpQueryOrderResonse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (b *Client) perpQueryByClientID(symbol, clientID string) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		opts := ClientOIDOpts{
+			Symbol:   symbol,
+			ClientID: clientID,
+		}
+		return b.do("future", http.MethodGet, "fapi/v1/order", opts, true, false)
+	}
+}
+
 func (b *Client) PerpQueryOrder(symbol string, oid int) (*PerpQueryOrderResonse, error) {
 	usymbol := strings.ToUpper(symbol)
 	opts := OIDOpts{
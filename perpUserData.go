@@ -6,6 +6,7 @@ import (
 
 	"errors"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -21,6 +22,7 @@ type perpUserDataBranch struct {
 	httpUpdateInterval int
 	errs               chan error
 	trades             userTradesBranch
+	events             perpEventsBranch
 }
 
 type perpAccountBranch struct {
@@ -91,6 +93,7 @@ type PerpAccountSnapShotOpts struct {
 
 func (u *Client) ClosePerpUserData() {
 	(*u.perpUser.cancel)()
+	u.perpUser.events.close()
 	u.perpUser.trades.Lock()
 	defer u.perpUser.trades.Unlock()
 	u.perpUser.trades.data = []TradeData{}
@@ -107,6 +110,23 @@ func (c *Client) GetPerpAccountData() (*PerpAccountResponse, error) {
 	return c.perpUser.account.Data, c.perpUser.readerrs()
 }
 
+// positionSide is BOTH in one-way mode and LONG or SHORT in hedge mode, false before InitPerpPrivateChannel
+func (c *Client) GetPerpPosition(symbol, positionSide string) (PositionsInAccount, bool) {
+	if c.perpUser == nil {
+		return PositionsInAccount{}, false
+	}
+	c.perpUser.account.RLock()
+	defer c.perpUser.account.RUnlock()
+	if c.perpUser.account.Data == nil {
+		return PositionsInAccount{}, false
+	}
+	idx := c.perpUser.account.Data.positionIndex(strings.ToUpper(symbol), strings.ToUpper(positionSide))
+	if idx == -1 {
+		return PositionsInAccount{}, false
+	}
+	return c.perpUser.account.Data.Positions[idx], true
+}
+
 func (c *Client) ReadPerpUserTrade() []TradeData {
 	c.perpUser.trades.RLock()
 	defer c.perpUser.trades.RUnlock()
@@ -146,6 +166,8 @@ func (c *Client) perpLocalUserData(logger *log.Logger) {
 	u.cancel = &cancel
 	u.httpUpdateInterval = 60
 	u.initialChannels()
+	// the stream reports to the branch before the first connection is done
+	c.perpUser = &u
 	userData := make(chan map[string]interface{}, 100)
 	// stream user data
 	go func() {
@@ -154,7 +176,7 @@ func (c *Client) perpLocalUserData(logger *log.Logger) {
 			case <-ctx.Done():
 				return
 			default:
-				res, err := c.GetListenKeyHub("perp", "") // delete listen key
+				res, err := c.WithContext(ctx).GetListenKeyHub("perp", "") // delete listen key
 				if err != nil {
 					logger.Println("retry listen key for user data stream in 5 sec..")
 					time.Sleep(time.Second * 5)
@@ -182,7 +204,6 @@ func (c *Client) perpLocalUserData(logger *log.Logger) {
 		}
 	}()
 	// wait for connecting
-	c.perpUser = &u
 	time.Sleep(time.Second * 5)
 }
 
@@ -202,6 +223,7 @@ func (u *perpUserDataBranch) maintainUserData(
 	client *Client,
 	userData *chan map[string]interface{},
 ) error {
+	client = client.WithContext(ctx)
 	// get the first snapshot to initial data struct
 	if err := u.getAccountSnapShot(client); err != nil {
 		return err
@@ -234,25 +256,7 @@ func (u *perpUserDataBranch) maintainUserData(
 			if !ok {
 				continue
 			}
-			switch event {
-			case "ACCOUNT_UPDATE":
-				if data, ok := message["a"].(map[string]interface{}); !ok {
-					continue
-				} else {
-					u.updateAccountData(&data)
-				}
-			case "ORDER_TRADE_UPDATE":
-				if event, ok := message["o"].(map[string]interface{}); ok {
-					// only handle trade now
-					if status, ok := event["X"].(string); ok {
-						if status == "FILLED" || status == "PARTIALLY_FILLED" {
-							u.handleTrade(&event)
-						}
-					}
-				}
-			default:
-				// pass
-			}
+			u.handleEvent(event, message)
 		}
 	}
 }
@@ -264,7 +268,7 @@ func (c *Client) perpUserData(ctx context.Context, listenKey string, logger *log
 	w.OnErr = false
 	var buffer bytes.Buffer
 	innerErr := make(chan error, 1)
-	buffer.WriteString("wss://fstream.binance.com/ws/")
+	buffer.WriteString(c.endpoints.stream("perp"))
 	buffer.WriteString(listenKey)
 	url := buffer.String()
 	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
@@ -288,7 +292,7 @@ func (c *Client) perpUserData(ctx context.Context, listenKey string, logger *log
 			case <-innerErr:
 				return
 			case <-putKey.C:
-				if err := c.PutListenKeyHub("perp", listenKey); err != nil {
+				if err := c.WithContext(ctx).PutListenKeyHub("perp", listenKey); err != nil {
 					// time out in 1 sec
 					w.Conn.SetReadDeadline(time.Now().Add(time.Second))
 				}
@@ -324,7 +328,12 @@ func (c *Client) perpUserData(ctx context.Context, listenKey string, logger *log
 				return err1
 			}
 			// check event time first
-			c.perpUser.handleUserData(&res, mainCh)
+			c.perpUser.handleUserData(ctx, &res, mainCh)
+			if event, _ := res["e"].(string); event == "listenKeyExpired" {
+				w.outBinanceErr()
+				innerErr <- errors.New("restart")
+				return errors.New("Binance perp listen key expired, reconnect...")
+			}
 			if err := w.Conn.SetReadDeadline(time.Now().Add(time.Second * duration)); err != nil {
 				innerErr <- errors.New("restart")
 				return err
@@ -333,14 +342,17 @@ func (c *Client) perpUserData(ctx context.Context, listenKey string, logger *log
 	}
 }
 
-func (u *perpUserDataBranch) handleUserData(res *map[string]interface{}, mainCh *chan map[string]interface{}) {
+func (u *perpUserDataBranch) handleUserData(ctx context.Context, res *map[string]interface{}, mainCh *chan map[string]interface{}) {
 	if eventTimeUnix, ok := (*res)["E"].(float64); ok {
 		eventTime := time.UnixMilli(int64(eventTimeUnix))
 		if time.Now().After(eventTime.Add(time.Minute * 5)) {
 			return
 		}
 		// insert to chan
-		*mainCh <- *res
+		select {
+		case *mainCh <- *res:
+		case <-ctx.Done():
+		}
 	}
 }
 
@@ -426,33 +438,106 @@ func (u *perpUserDataBranch) handleTrade(res *map[string]interface{}) {
 	if orderType, ok := (*res)["o"].(string); ok {
 		data.OrderType = orderType
 	}
+	if positionSide, ok := (*res)["ps"].(string); ok {
+		data.PositionSide = positionSide
+	}
 	u.insertTrade(&data)
 }
 
-func (u *perpUserDataBranch) updateAccountData(message *map[string]interface{}) {
-	u.account.Lock()
-	defer u.account.Unlock()
-	if balances, ok := (*message)["B"].([]interface{}); ok {
-		for _, item := range balances {
-			data := item.(map[string]interface{})
-			for idx, asset := range u.account.Data.Assets {
-				if asset.Asset == data["a"] {
-					u.account.Data.Assets[idx].WalletBalance = data["wb"].(string)
-					u.account.Data.Assets[idx].CrossWalletBalance = data["cw"].(string)
+func (a *PerpAccountResponse) positionIndex(symbol, positionSide string) int {
+	for idx, position := range a.Positions {
+		if position.Symbol == symbol && position.PositionSide == positionSide {
+			return idx
+		}
+	}
+	return -1
+}
+
+func (u *perpUserDataBranch) handleEvent(event string, message map[string]interface{}) {
+	out := PerpUserEvent{
+		Type: event,
+	}
+	var err error
+	switch event {
+	case "ACCOUNT_UPDATE":
+		if out.Account, err = parsePerpAccountUpdate(message); err == nil {
+			u.updateAccountData(out.Account)
+		}
+	case "ORDER_TRADE_UPDATE":
+		if out.Order, err = parsePerpOrderUpdate(message); err == nil {
+			if out.Order.Status == "FILLED" || out.Order.Status == "PARTIALLY_FILLED" {
+				if order, ok := message["o"].(map[string]interface{}); ok {
+					u.handleTrade(&order)
 				}
 			}
 		}
+	case "MARGIN_CALL":
+		out.MarginCall, err = parsePerpMarginCall(message)
+	case "ACCOUNT_CONFIG_UPDATE":
+		if out.Config, err = parsePerpAccountConfigUpdate(message); err == nil {
+			u.updateLeverage(out.Config)
+		}
+	case "listenKeyExpired":
+		// the stream reconnects by itself
+	default:
+		return
 	}
-	if positions, ok := (*message)["P"].([]interface{}); ok {
-		for _, item := range positions {
-			data := item.(map[string]interface{})
-			for idx, position := range u.account.Data.Positions {
-				if position.Symbol == data["s"] {
-					u.account.Data.Positions[idx].PositionAmt = data["pa"].(string)
-					u.account.Data.Positions[idx].EntryPrice = data["ep"].(string)
-					u.account.Data.Positions[idx].UnrealizedProfit = data["up"].(string)
-				}
+	if err != nil {
+		u.insertErr(err)
+		return
+	}
+	if eventTime, ok := message["E"].(float64); ok {
+		out.EventTime = time.UnixMilli(int64(eventTime))
+	}
+	u.events.publish(out)
+}
+
+func (u *perpUserDataBranch) updateAccountData(update *PerpAccountUpdate) {
+	u.account.Lock()
+	defer u.account.Unlock()
+	if u.account.Data == nil {
+		return
+	}
+	for _, balance := range update.Balances {
+		for idx, asset := range u.account.Data.Assets {
+			if asset.Asset == balance.Asset {
+				u.account.Data.Assets[idx].WalletBalance = balance.WalletBalance.String()
+				u.account.Data.Assets[idx].CrossWalletBalance = balance.CrossWalletBalance.String()
 			}
 		}
 	}
+	for _, position := range update.Positions {
+		// hedge mode has a LONG and a SHORT position of the same symbol
+		idx := u.account.Data.positionIndex(position.Symbol, position.PositionSide)
+		if idx == -1 {
+			u.account.Data.Positions = append(u.account.Data.Positions, PositionsInAccount{
+				Symbol:       position.Symbol,
+				PositionSide: position.PositionSide,
+			})
+			idx = len(u.account.Data.Positions) - 1
+		}
+		u.account.Data.Positions[idx].PositionAmt = position.PositionAmt.String()
+		u.account.Data.Positions[idx].EntryPrice = position.EntryPrice.String()
+		u.account.Data.Positions[idx].UnrealizedProfit = position.UnrealizedProfit.String()
+		if position.MarginType != "" {
+			u.account.Data.Positions[idx].Isolated = position.MarginType == "isolated"
+		}
+	}
+}
+
+// every side of the symbol has the same leverage
+func (u *perpUserDataBranch) updateLeverage(update *PerpAccountConfigUpdate) {
+	if update.Symbol == "" {
+		return
+	}
+	u.account.Lock()
+	defer u.account.Unlock()
+	if u.account.Data == nil {
+		return
+	}
+	for idx, position := range u.account.Data.Positions {
+		if position.Symbol == update.Symbol {
+			u.account.Data.Positions[idx].Leverage = strconv.Itoa(update.Leverage)
+		}
+	}
 }
@@ -0,0 +1,445 @@
+package bnnapi
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// ORDER_TRADE_UPDATE event of the perp user data stream, one for every change of an order
+type PerpOrderUpdate struct {
+	Symbol        string
+	OrderID       int64
+	ClientOrderID string
+	Side          string
+	// BOTH in one-way mode, LONG or SHORT in hedge mode
+	PositionSide string
+	Type         string
+	// the type when placed, a triggered STOP_MARKET is MARKET in Type
+	OrigType    string
+	TimeInForce string
+	Price       decimal.Decimal
+	AvgPrice    decimal.Decimal
+	Qty         decimal.Decimal
+	// NEW, CANCELED, CALCULATED, EXPIRED, TRADE or AMENDMENT
+	ExecutionType string
+	// NEW, PARTIALLY_FILLED, FILLED, CANCELED, EXPIRED or EXPIRED_IN_MATCH
+	Status        string
+	CumulativeQty decimal.Decimal
+	// the last fill, zero unless ExecutionType is TRADE
+	LastQty         decimal.Decimal
+	LastPrice       decimal.Decimal
+	Commission      decimal.Decimal
+	CommissionAsset string
+	RealizedPnl     decimal.Decimal
+	TradeID         int64
+	IsMaker         bool
+	ReduceOnly      bool
+	ClosePosition   bool
+	// stop orders, which price triggers them
+	StopPrice    decimal.Decimal
+	WorkingType  string
+	PriceProtect bool
+	// trailing stop orders
+	ActivationPrice decimal.Decimal
+	CallbackRate    decimal.Decimal
+	BidsNotional    decimal.Decimal
+	AsksNotional    decimal.Decimal
+	// NONE, EXPIRE_TAKER, EXPIRE_MAKER or EXPIRE_BOTH
+	SelfTradePreventionMode string
+	PriceMatch              string
+	GoodTillDate            int64
+	EventTime               time.Time
+	TransactionTime         time.Time
+}
+
+type PerpBalanceUpdate struct {
+	Asset              string
+	WalletBalance      decimal.Decimal
+	CrossWalletBalance decimal.Decimal
+	// the change of the balance besides pnl and commission
+	BalanceChange decimal.Decimal
+}
+
+type PerpPositionUpdate struct {
+	Symbol       string
+	PositionSide string
+	PositionAmt  decimal.Decimal
+	EntryPrice   decimal.Decimal
+	// break even price
+	BreakEvenPrice   decimal.Decimal
+	AccumulatedPnl   decimal.Decimal
+	UnrealizedProfit decimal.Decimal
+	// isolated or cross
+	MarginType     string
+	IsolatedWallet decimal.Decimal
+}
+
+// ACCOUNT_UPDATE event, only the changed balances and positions are in it
+type PerpAccountUpdate struct {
+	// DEPOSIT, WITHDRAW, ORDER, FUNDING_FEE, WITHDRAW_REJECT, ADJUSTMENT, INSURANCE_CLEAR, ADMIN_DEPOSIT,
+	// ADMIN_WITHDRAW, MARGIN_TRANSFER, MARGIN_TYPE_CHANGE, ASSET_TRANSFER, OPTIONS_PREMIUM_FEE, OPTIONS_SETTLE_PROFIT,
+	// AUTO_EXCHANGE or COIN_SWAP_DEPOSIT/WITHDRAW
+	Reason          string
+	Balances        []PerpBalanceUpdate
+	Positions       []PerpPositionUpdate
+	EventTime       time.Time
+	TransactionTime time.Time
+}
+
+type PerpMarginCallPosition struct {
+	Symbol                string
+	PositionSide          string
+	PositionAmt           decimal.Decimal
+	MarginType            string
+	IsolatedWallet        decimal.Decimal
+	MarkPrice             decimal.Decimal
+	UnrealizedProfit      decimal.Decimal
+	MaintenanceMarginRate decimal.Decimal
+}
+
+// MARGIN_CALL event, the positions are close to liquidation
+type PerpMarginCall struct {
+	// empty for isolated positions
+	CrossWalletBalance decimal.Decimal
+	Positions          []PerpMarginCallPosition
+	EventTime          time.Time
+}
+
+// ACCOUNT_CONFIG_UPDATE event, either the leverage of a symbol or the multi-assets mode changed
+type PerpAccountConfigUpdate struct {
+	// empty when the multi-assets mode changed
+	Symbol   string
+	Leverage int
+	// set when the event is about the multi-assets mode
+	MultiAssetsChanged bool
+	MultiAssetsMode    bool
+	EventTime          time.Time
+	TransactionTime    time.Time
+}
+
+// one event of the perp user data stream, only the field of its Type is set
+type PerpUserEvent struct {
+	// ORDER_TRADE_UPDATE, ACCOUNT_UPDATE, MARGIN_CALL, ACCOUNT_CONFIG_UPDATE or listenKeyExpired
+	Type       string
+	EventTime  time.Time
+	Order      *PerpOrderUpdate
+	Account    *PerpAccountUpdate
+	MarginCall *PerpMarginCall
+	Config     *PerpAccountConfigUpdate
+}
+
+type perpEventsBranch struct {
+	sync.Mutex
+	subs   []chan PerpUserEvent
+	closed bool
+}
+
+// every event in the order of the stream, the channel is closed by ClosePerpUserData.
+// The stream never waits for a subscriber, one whose buffer is full is closed and dropped,
+// subscribe again and resync from GetPerpAccountData then.
+// Malformed events are not sent, their errors come with GetPerpAccountData.
+// The stream reconnects with a new listen key on listenKeyExpired.
+func (c *Client) SubscribePerpUserEvents(buffer int) <-chan PerpUserEvent {
+	return c.perpUser.events.subscribe(buffer)
+}
+
+// internal funcs ------------------------------------------------
+
+func (e *perpEventsBranch) subscribe(buffer int) <-chan PerpUserEvent {
+	ch := make(chan PerpUserEvent, buffer)
+	e.Lock()
+	defer e.Unlock()
+	if e.closed {
+		close(ch)
+		return ch
+	}
+	e.subs = append(e.subs, ch)
+	return ch
+}
+
+// a subscriber which is full has missed the event, close it so it knows
+func (e *perpEventsBranch) publish(event PerpUserEvent) {
+	e.Lock()
+	defer e.Unlock()
+	subs := e.subs[:0]
+	for _, ch := range e.subs {
+		select {
+		case ch <- event:
+			subs = append(subs, ch)
+		default:
+			close(ch)
+		}
+	}
+	e.subs = subs
+}
+
+func (e *perpEventsBranch) close() {
+	e.Lock()
+	defer e.Unlock()
+	for _, ch := range e.subs {
+		close(ch)
+	}
+	e.subs = nil
+	e.closed = true
+}
+
+// eventReader reads the fields of a stream message, a missing field is zero
+// while a field of an unexpected type makes the whole message malformed
+type eventReader struct {
+	event string
+	m     map[string]interface{}
+	err   error
+}
+
+func (r *eventReader) fail(key string) {
+	if r.err == nil {
+		r.err = fmt.Errorf("malformed %s event: unexpected type of %q: %T", r.event, key, r.m[key])
+	}
+}
+
+func (r *eventReader) str(key string) string {
+	value, ok := r.m[key]
+	if !ok || value == nil {
+		return ""
+	}
+	s, ok := value.(string)
+	if !ok {
+		r.fail(key)
+	}
+	return s
+}
+
+func (r *eventReader) dec(key string) decimal.Decimal {
+	s := r.str(key)
+	if s == "" {
+		return decimal.Zero
+	}
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		r.fail(key)
+	}
+	return d
+}
+
+func (r *eventReader) num(key string) int64 {
+	value, ok := r.m[key]
+	if !ok || value == nil {
+		return 0
+	}
+	f, ok := value.(float64)
+	if !ok {
+		r.fail(key)
+	}
+	return int64(f)
+}
+
+func (r *eventReader) flag(key string) bool {
+	value, ok := r.m[key]
+	if !ok || value == nil {
+		return false
+	}
+	b, ok := value.(bool)
+	if !ok {
+		r.fail(key)
+	}
+	return b
+}
+
+func (r *eventReader) time(key string) time.Time {
+	ms := r.num(key)
+	if ms == 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
+
+func (r *eventReader) object(key string) *eventReader {
+	value, ok := r.m[key].(map[string]interface{})
+	if !ok {
+		r.fail(key)
+	}
+	return &eventReader{event: r.event, m: value}
+}
+
+func (r *eventReader) list(key string) []*eventReader {
+	value, ok := r.m[key]
+	if !ok || value == nil {
+		return nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		r.fail(key)
+		return nil
+	}
+	readers := make([]*eventReader, 0, len(items))
+	for _, item := range items {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			r.fail(key)
+			return nil
+		}
+		readers = append(readers, &eventReader{event: r.event, m: m})
+	}
+	return readers
+}
+
+// the first error of the reader or of its children
+func firstErr(readers ...*eventReader) error {
+	for _, r := range readers {
+		if r.err != nil {
+			return r.err
+		}
+	}
+	return nil
+}
+
+func parsePerpOrderUpdate(message map[string]interface{}) (*PerpOrderUpdate, error) {
+	r := &eventReader{event: "ORDER_TRADE_UPDATE", m: message}
+	o := r.object("o")
+	if r.err != nil {
+		return nil, r.err
+	}
+	update := &PerpOrderUpdate{
+		Symbol:                  o.str("s"),
+		OrderID:                 o.num("i"),
+		ClientOrderID:           o.str("c"),
+		Side:                    o.str("S"),
+		PositionSide:            o.str("ps"),
+		Type:                    o.str("o"),
+		OrigType:                o.str("ot"),
+		TimeInForce:             o.str("f"),
+		Price:                   o.dec("p"),
+		AvgPrice:                o.dec("ap"),
+		Qty:                     o.dec("q"),
+		ExecutionType:           o.str("x"),
+		Status:                  o.str("X"),
+		CumulativeQty:           o.dec("z"),
+		LastQty:                 o.dec("l"),
+		LastPrice:               o.dec("L"),
+		Commission:              o.dec("n"),
+		CommissionAsset:         o.str("N"),
+		RealizedPnl:             o.dec("rp"),
+		TradeID:                 o.num("t"),
+		IsMaker:                 o.flag("m"),
+		ReduceOnly:              o.flag("R"),
+		ClosePosition:           o.flag("cp"),
+		StopPrice:               o.dec("sp"),
+		WorkingType:             o.str("wt"),
+		PriceProtect:            o.flag("pP"),
+		ActivationPrice:         o.dec("AP"),
+		CallbackRate:            o.dec("cr"),
+		BidsNotional:            o.dec("b"),
+		AsksNotional:            o.dec("a"),
+		SelfTradePreventionMode: o.str("V"),
+		PriceMatch:              o.str("pm"),
+		GoodTillDate:            o.num("gtd"),
+		EventTime:               r.time("E"),
+		TransactionTime:         r.time("T"),
+	}
+	if err := firstErr(r, o); err != nil {
+		return nil, err
+	}
+	if update.Symbol == "" || update.OrderID == 0 {
+		return nil, fmt.Errorf("malformed ORDER_TRADE_UPDATE event: no symbol or order id")
+	}
+	return update, nil
+}
+
+func parsePerpAccountUpdate(message map[string]interface{}) (*PerpAccountUpdate, error) {
+	r := &eventReader{event: "ACCOUNT_UPDATE", m: message}
+	a := r.object("a")
+	if r.err != nil {
+		return nil, r.err
+	}
+	update := &PerpAccountUpdate{
+		Reason:          a.str("m"),
+		EventTime:       r.time("E"),
+		TransactionTime: r.time("T"),
+	}
+	readers := []*eventReader{r, a}
+	for _, b := range a.list("B") {
+		update.Balances = append(update.Balances, PerpBalanceUpdate{
+			Asset:              b.str("a"),
+			WalletBalance:      b.dec("wb"),
+			CrossWalletBalance: b.dec("cw"),
+			BalanceChange:      b.dec("bc"),
+		})
+		readers = append(readers, b)
+	}
+	for _, p := range a.list("P") {
+		position := PerpPositionUpdate{
+			Symbol:           p.str("s"),
+			PositionSide:     p.str("ps"),
+			PositionAmt:      p.dec("pa"),
+			EntryPrice:       p.dec("ep"),
+			BreakEvenPrice:   p.dec("bep"),
+			AccumulatedPnl:   p.dec("cr"),
+			UnrealizedProfit: p.dec("up"),
+			MarginType:       p.str("mt"),
+			IsolatedWallet:   p.dec("iw"),
+		}
+		if position.PositionSide == "" {
+			position.PositionSide = "BOTH"
+		}
+		update.Positions = append(update.Positions, position)
+		readers = append(readers, p)
+	}
+	if err := firstErr(readers...); err != nil {
+		return nil, err
+	}
+	return update, nil
+}
+
+func parsePerpMarginCall(message map[string]interface{}) (*PerpMarginCall, error) {
+	r := &eventReader{event: "MARGIN_CALL", m: message}
+	call := &PerpMarginCall{
+		CrossWalletBalance: r.dec("cw"),
+		EventTime:          r.time("E"),
+	}
+	readers := []*eventReader{r}
+	for _, p := range r.list("p") {
+		call.Positions = append(call.Positions, PerpMarginCallPosition{
+			Symbol:                p.str("s"),
+			PositionSide:          p.str("ps"),
+			PositionAmt:           p.dec("pa"),
+			MarginType:            p.str("mt"),
+			IsolatedWallet:        p.dec("iw"),
+			MarkPrice:             p.dec("mp"),
+			UnrealizedProfit:      p.dec("up"),
+			MaintenanceMarginRate: p.dec("mm"),
+		})
+		readers = append(readers, p)
+	}
+	if err := firstErr(readers...); err != nil {
+		return nil, err
+	}
+	return call, nil
+}
+
+func parsePerpAccountConfigUpdate(message map[string]interface{}) (*PerpAccountConfigUpdate, error) {
+	r := &eventReader{event: "ACCOUNT_CONFIG_UPDATE", m: message}
+	update := &PerpAccountConfigUpdate{
+		EventTime:       r.time("E"),
+		TransactionTime: r.time("T"),
+	}
+	readers := []*eventReader{r}
+	if _, ok := message["ac"]; ok {
+		ac := r.object("ac")
+		update.Symbol = ac.str("s")
+		update.Leverage = int(ac.num("l"))
+		readers = append(readers, ac)
+	}
+	if _, ok := message["ai"]; ok {
+		ai := r.object("ai")
+		update.MultiAssetsMode = ai.flag("j")
+		update.MultiAssetsChanged = true
+		readers = append(readers, ai)
+	}
+	if err := firstErr(readers...); err != nil {
+		return nil, err
+	}
+	return update, nil
+}
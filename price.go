@@ -1,6 +1,9 @@
 package bnnapi
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
 
 type SymbolPriceOpts struct {
 	Symbol string `url:"symbol"`
@@ -48,6 +51,29 @@ func (b *Client) SpotOrderBookTickers() ([]*SymbolOrderBookTicker, error) {
 	return prices, nil
 }
 
+// average price of the last "mins" minutes, the reference of PERCENT_PRICE and the notional of market orders
+func (b *Client) SpotAvgPrice(symbol string) (*SpotAvgPriceResponse, error) {
+	opts := SymbolPriceOpts{
+		Symbol: strings.ToUpper(symbol),
+	}
+	res, err := b.do("spot", http.MethodGet, "api/v3/avgPrice", opts, false, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := &SpotAvgPriceResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+type SpotAvgPriceResponse struct {
+	Mins      int    `json:"mins"`
+	Price     string `json:"price"`
+	CloseTime int64  `json:"closeTime"`
+}
+
 type SymbolPrice struct {
 	Symbol string
 	Price  string
@@ -111,6 +137,23 @@ func (b *Client) SwapMarkPrices(symbol string) ([]*SwapMarkPriceResponse, error)
 	return prices, nil
 }
 
+// mark price of one symbol, premiumIndex sends an object instead of an array for it
+func (b *Client) SwapMarkPrice(symbol string) (*SwapMarkPriceResponse, error) {
+	opts := SwapMarkPriceOpts{
+		Symbol: strings.ToUpper(symbol),
+	}
+	res, err := b.do("future", http.MethodGet, "fapi/v1/premiumIndex", opts, false, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := &SwapMarkPriceResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 type SwapMarkPriceOpts struct {
 	Symbol string `url:"symbol"`
 }
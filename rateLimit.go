@@ -0,0 +1,540 @@
+package bnnapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrRateLimited = errors.New("binance rate limit reached")
+
+type RateLimit struct {
+	RateLimitType string `json:"rateLimitType"` // REQUEST_WEIGHT, ORDERS, RAW_REQUESTS
+	Interval      string `json:"interval"`      // SECOND, MINUTE, DAY
+	IntervalNum   int    `json:"intervalNum"`
+	Limit         int    `json:"limit"`
+}
+
+type RateLimitUsage struct {
+	RateLimitType string
+	Interval      time.Duration
+	Used          int
+	Limit         int
+	ResetAt       time.Time
+}
+
+// RateLimiter tracks the used request weight and order count of spot and futures separately.
+// It is fed by the X-MBX-USED-WEIGHT-* and X-MBX-ORDER-COUNT-* response headers,
+// and reserves the weight of every request before it is sent.
+// The weight is per ip and the order count is per account, so one limiter should be shared
+// by every client of the process and it keeps the order counts of every api key apart,
+// New uses the package default one.
+type RateLimiter struct {
+	mux    sync.Mutex
+	wait   bool
+	spot   *limitBranch
+	future *limitBranch
+}
+
+type limitBranch struct {
+	// request weight and raw requests of the ip
+	counters []*rateCounter
+	// ORDERS limits, the counters of each api key are made from them
+	orderLimits []RateLimit
+	orders      map[string][]*rateCounter
+	bannedUntil time.Time
+}
+
+type rateCounter struct {
+	typ      string
+	interval time.Duration
+	limit    int
+	used     int
+	start    time.Time
+	header   string
+}
+
+var defaultRateLimiter = NewRateLimiter(true)
+
+var defaultSpotRateLimits = []RateLimit{
+	{RateLimitType: "REQUEST_WEIGHT", Interval: "MINUTE", IntervalNum: 1, Limit: 6000},
+	{RateLimitType: "ORDERS", Interval: "SECOND", IntervalNum: 10, Limit: 100},
+	{RateLimitType: "ORDERS", Interval: "DAY", IntervalNum: 1, Limit: 200000},
+	{RateLimitType: "RAW_REQUESTS", Interval: "MINUTE", IntervalNum: 5, Limit: 61000},
+}
+
+var defaultFutureRateLimits = []RateLimit{
+	{RateLimitType: "REQUEST_WEIGHT", Interval: "MINUTE", IntervalNum: 1, Limit: 2400},
+	{RateLimitType: "ORDERS", Interval: "MINUTE", IntervalNum: 1, Limit: 1200},
+	{RateLimitType: "ORDERS", Interval: "SECOND", IntervalNum: 10, Limit: 300},
+}
+
+// wait true will block the request until the window resets, false will fail fast with ErrRateLimited
+func NewRateLimiter(wait bool) *RateLimiter {
+	l := &RateLimiter{
+		wait:   wait,
+		spot:   &limitBranch{orders: make(map[string][]*rateCounter)},
+		future: &limitBranch{orders: make(map[string][]*rateCounter)},
+	}
+	l.SetLimits("spot", defaultSpotRateLimits)
+	l.SetLimits("future", defaultFutureRateLimits)
+	return l
+}
+
+// nil will disable the limiter
+func (c *Client) SetRateLimiter(l *RateLimiter) {
+	c.limiter = l
+}
+
+func (c *Client) RateLimiter() *RateLimiter {
+	return c.limiter
+}
+
+func (l *RateLimiter) SetWait(wait bool) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.wait = wait
+}
+
+// replace the limits of "spot" or "future", the used counts are kept
+func (l *RateLimiter) SetLimits(product string, limits []RateLimit) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	branch := l.branch(product)
+	if branch == nil {
+		return
+	}
+	var ipLimits, orderLimits []RateLimit
+	for _, limit := range limits {
+		if limit.RateLimitType == "ORDERS" {
+			orderLimits = append(orderLimits, limit)
+		} else {
+			ipLimits = append(ipLimits, limit)
+		}
+	}
+	branch.counters = newRateCounters(ipLimits, branch.counters)
+	branch.orderLimits = orderLimits
+	for key, old := range branch.orders {
+		branch.orders[key] = newRateCounters(orderLimits, old)
+	}
+}
+
+func (l *RateLimiter) LoadSpotLimits(info *SpotExchangeInfo) {
+	l.SetLimits("spot", info.RateLimits)
+}
+
+func (l *RateLimiter) LoadSwapLimits(info *SwapExchangeInfo) {
+	l.SetLimits("future", info.RateLimits)
+}
+
+// current usage of "spot" or "future" by the ip, the order counts are in OrderUsage
+func (l *RateLimiter) Usage(product string) []RateLimitUsage {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	branch := l.branch(product)
+	if branch == nil {
+		return nil
+	}
+	return rateUsage(branch.counters, time.Now())
+}
+
+// current order count of "spot" or "future" by the account of the api key
+func (l *RateLimiter) OrderUsage(product, apiKey string) []RateLimitUsage {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	branch := l.branch(product)
+	if branch == nil {
+		return nil
+	}
+	return rateUsage(branch.account(apiKey), time.Now())
+}
+
+// the time a 418, 429 or -1003 told us to stay away until
+func (l *RateLimiter) BannedUntil(product string) time.Time {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	branch := l.branch(product)
+	if branch == nil {
+		return time.Time{}
+	}
+	return branch.bannedUntil
+}
+
+// internal funcs ------------------------------------------------
+
+func (l *RateLimiter) branch(product string) *limitBranch {
+	switch product {
+	case "spot":
+		return l.spot
+	case "future":
+		return l.future
+	}
+	return nil
+}
+
+// the order counters of the api key, made on first use
+func (b *limitBranch) account(apiKey string) []*rateCounter {
+	counters, ok := b.orders[apiKey]
+	if !ok {
+		counters = newRateCounters(b.orderLimits, nil)
+		b.orders[apiKey] = counters
+	}
+	return counters
+}
+
+// the ip counters followed by the order counters of the api key
+func (b *limitBranch) all(apiKey string) []*rateCounter {
+	counters := make([]*rateCounter, 0, len(b.counters)+len(b.orderLimits))
+	counters = append(counters, b.counters...)
+	return append(counters, b.account(apiKey)...)
+}
+
+// reserve the weight and order count before sending
+func (l *RateLimiter) acquire(ctx context.Context, product, apiKey string, weight, orders int) error {
+	for {
+		l.mux.Lock()
+		branch := l.branch(product)
+		if branch == nil {
+			l.mux.Unlock()
+			return nil
+		}
+		now := time.Now()
+		if now.Before(branch.bannedUntil) {
+			until := branch.bannedUntil
+			l.mux.Unlock()
+			return fmt.Errorf("%w: %s banned until %s", ErrRateLimited, product, until.Format(time.RFC3339))
+		}
+		counters := branch.all(apiKey)
+		var blocked *rateCounter
+		for _, counter := range counters {
+			counter.rotate(now)
+			if counter.used > 0 && counter.used+counter.cost(weight, orders) > counter.limit {
+				blocked = counter
+				break
+			}
+		}
+		if blocked == nil {
+			for _, counter := range counters {
+				counter.used += counter.cost(weight, orders)
+			}
+			l.mux.Unlock()
+			return nil
+		}
+		wait := l.wait
+		resetAt := blocked.start.Add(blocked.interval)
+		l.mux.Unlock()
+		if !wait {
+			return fmt.Errorf("%w: %s %s %d/%d in %s", ErrRateLimited, product, blocked.typ, blocked.used, blocked.limit, blocked.interval)
+		}
+		timer := time.NewTimer(time.Until(resetAt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// sync the counters with the headers of the response
+func (l *RateLimiter) update(product, apiKey string, resp *http.Response, apiErr *APIError) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	branch := l.branch(product)
+	if branch == nil {
+		return
+	}
+	now := time.Now()
+	for _, counter := range branch.all(apiKey) {
+		counter.rotate(now)
+		if counter.header == "" {
+			continue
+		}
+		value := resp.Header.Get(counter.header)
+		if value == "" {
+			continue
+		}
+		used, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		// keep the local count if it is higher, the other in flight requests are not in the header yet
+		if used > counter.used {
+			counter.used = used
+		}
+	}
+	// -1015 is the order count of the account, the header above has it already
+	if apiErr != nil && isIPBanned(apiErr) {
+		until := now.Add(apiErr.RetryAfter)
+		if apiErr.RetryAfter == 0 {
+			until = now.Truncate(time.Minute).Add(time.Minute)
+		}
+		if until.After(branch.bannedUntil) {
+			branch.bannedUntil = until
+		}
+	}
+}
+
+// 418, 429 and -1003 stop every request from the ip
+func isIPBanned(apiErr *APIError) bool {
+	switch {
+	case apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode == http.StatusTeapot:
+		return true
+	case apiErr.Code == ErrCodeTooManyRequests:
+		return true
+	}
+	return false
+}
+
+// counters of the limits, the used counts of the old ones with the same type and interval are kept
+func newRateCounters(limits []RateLimit, old []*rateCounter) []*rateCounter {
+	var counters []*rateCounter
+	for _, limit := range limits {
+		interval := rateLimitInterval(limit)
+		if interval == 0 {
+			continue
+		}
+		counter := &rateCounter{
+			typ:      limit.RateLimitType,
+			interval: interval,
+			limit:    limit.Limit,
+			header:   rateLimitHeader(limit),
+		}
+		for _, o := range old {
+			if o.typ == counter.typ && o.interval == counter.interval {
+				counter.used = o.used
+				counter.start = o.start
+			}
+		}
+		counters = append(counters, counter)
+	}
+	return counters
+}
+
+func rateUsage(counters []*rateCounter, now time.Time) []RateLimitUsage {
+	var usage []RateLimitUsage
+	for _, counter := range counters {
+		counter.rotate(now)
+		usage = append(usage, RateLimitUsage{
+			RateLimitType: counter.typ,
+			Interval:      counter.interval,
+			Used:          counter.used,
+			Limit:         counter.limit,
+			ResetAt:       counter.start.Add(counter.interval),
+		})
+	}
+	return usage
+}
+
+func (r *rateCounter) rotate(now time.Time) {
+	start := now.Truncate(r.interval)
+	if !start.Equal(r.start) {
+		r.start = start
+		r.used = 0
+	}
+}
+
+func (r *rateCounter) cost(weight, orders int) int {
+	switch r.typ {
+	case "REQUEST_WEIGHT":
+		return weight
+	case "ORDERS":
+		return orders
+	case "RAW_REQUESTS":
+		return 1
+	}
+	return 0
+}
+
+func rateLimitInterval(limit RateLimit) time.Duration {
+	var unit time.Duration
+	switch limit.Interval {
+	case "SECOND":
+		unit = time.Second
+	case "MINUTE":
+		unit = time.Minute
+	case "HOUR":
+		unit = time.Hour
+	case "DAY":
+		unit = time.Hour * 24
+	default:
+		return 0
+	}
+	return unit * time.Duration(limit.IntervalNum)
+}
+
+// e.g. X-MBX-USED-WEIGHT-1M, X-MBX-ORDER-COUNT-10S
+func rateLimitHeader(limit RateLimit) string {
+	var prefix string
+	switch limit.RateLimitType {
+	case "REQUEST_WEIGHT":
+		prefix = "X-MBX-USED-WEIGHT-"
+	case "ORDERS":
+		prefix = "X-MBX-ORDER-COUNT-"
+	default:
+		return ""
+	}
+	if limit.Interval == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s%d%s", prefix, limit.IntervalNum, limit.Interval[:1])
+}
+
+// request weight and order count of an endpoint, sapi is limited separately and not tracked
+func requestCost(product, method, path string, values url.Values) (weight, orders int) {
+	path = strings.TrimPrefix(path, "/")
+	if isOrderPlacement(method, path) {
+		orders = 1
+		switch path {
+		case "api/v3/orderList/oco", "api/v3/orderList/oto":
+			orders = 2
+		case "api/v3/order/amend/keepPriority":
+			// not counted as a new order
+			orders = 0
+		case "api/v3/orderList/otoco":
+			orders = 3
+			if values.Get("pendingBelowType") == "" {
+				orders = 2
+			}
+		}
+		if path == "fapi/v1/batchOrders" {
+			var batch []interface{}
+			if err := json.Unmarshal([]byte(values.Get("batchOrders")), &batch); err == nil {
+				orders = len(batch)
+			}
+		}
+	}
+	switch product {
+	case "spot":
+		if strings.HasPrefix(path, "sapi/") {
+			return 0, 0
+		}
+		return spotWeight(method, path, values), orders
+	case "future":
+		return futureWeight(method, path, values), orders
+	}
+	return 0, 0
+}
+
+func spotWeight(method, path string, values url.Values) int {
+	hasSymbol := values.Get("symbol") != ""
+	limit, _ := strconv.Atoi(values.Get("limit"))
+	switch path {
+	case "api/v3/depth":
+		switch {
+		case limit <= 100:
+			return 5
+		case limit <= 500:
+			return 25
+		case limit <= 1000:
+			return 50
+		}
+		return 250
+	case "api/v3/exchangeInfo", "api/v3/account", "api/v3/allOrders", "api/v3/myTrades",
+		"api/v3/allOrderList", "api/v3/myPreventedMatches":
+		return 20
+	case "api/v3/ticker/price", "api/v3/ticker/bookTicker":
+		if hasSymbol {
+			return 2
+		}
+		return 4
+	case "api/v3/klines", "api/v3/userDataStream", "api/v3/avgPrice":
+		return 2
+	case "api/v3/openOrders":
+		if method != http.MethodGet {
+			return 1
+		}
+		if hasSymbol {
+			return 6
+		}
+		return 80
+	case "api/v3/order", "api/v3/orderList":
+		if method == http.MethodGet {
+			return 4
+		}
+		return 1
+	case "api/v3/openOrderList":
+		return 6
+	case "api/v3/order/amend/keepPriority":
+		return 4
+	case "api/v3/order/test":
+		if values.Get("computeCommissionRates") == "true" {
+			return 20
+		}
+		return 1
+	}
+	return 1
+}
+
+func futureWeight(method, path string, values url.Values) int {
+	hasSymbol := values.Get("symbol") != ""
+	limit, _ := strconv.Atoi(values.Get("limit"))
+	switch path {
+	case "fapi/v1/depth":
+		if limit == 0 {
+			// the default limit
+			limit = 500
+		}
+		switch {
+		case limit <= 50:
+			return 2
+		case limit <= 100:
+			return 5
+		case limit <= 500:
+			return 10
+		}
+		return 20
+	case "fapi/v1/klines":
+		switch {
+		case limit < 100:
+			return 1
+		case limit < 500:
+			return 2
+		case limit <= 1000:
+			return 5
+		}
+		return 10
+	case "fapi/v1/ticker/price":
+		if hasSymbol {
+			return 1
+		}
+		return 2
+	case "fapi/v1/ticker/bookTicker":
+		if hasSymbol {
+			return 2
+		}
+		return 5
+	case "fapi/v1/premiumIndex":
+		if hasSymbol {
+			return 1
+		}
+		return 10
+	case "fapi/v1/openOrders":
+		if hasSymbol {
+			return 1
+		}
+		return 40
+	case "fapi/v2/account", "fapi/v2/balance", "fapi/v2/positionRisk", "fapi/v1/allOrders",
+		"fapi/v1/userTrades", "fapi/v1/batchOrders", "fapi/v1/adlQuantile":
+		return 5
+	case "fapi/v1/countdownCancelAll":
+		return 10
+	case "fapi/v1/forceOrders":
+		if hasSymbol {
+			return 20
+		}
+		return 50
+	case "fapi/v1/income", "fapi/v1/positionSide/dual":
+		if method == http.MethodGet {
+			return 30
+		}
+		return 1
+	}
+	return 1
+}
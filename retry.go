@@ -0,0 +1,169 @@
+package bnnapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+var ErrOrderStatusUnknown = errors.New("order status unknown")
+
+// RetryPolicy is opt-in, only GET requests are resent.
+// Order placement is never blindly resent, the order is looked up by its client order id first.
+type RetryPolicy struct {
+	// including the first try
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+	Multiplier:     2,
+}
+
+// nil will disable retrying, which is the default
+func (c *Client) SetRetryPolicy(p *RetryPolicy) {
+	c.retry = p
+}
+
+// a random id which fits the ^[\.A-Z\:/a-z0-9_-]{1,36}$ rule
+func NewClientOrderID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("bnn-%d", time.Now().UnixNano())
+	}
+	return "bnn-" + hex.EncodeToString(b)
+}
+
+// internal funcs ------------------------------------------------
+
+// orders get a generated client order id once a retry policy is set
+func (c *Client) orderClientID(clientID string) string {
+	if clientID == "" && c.retry != nil {
+		return NewClientOrderID()
+	}
+	return clientID
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.InitialBackoff
+	for i := 0; i < attempt; i++ {
+		wait = time.Duration(float64(wait) * p.Multiplier)
+		if wait > p.MaxBackoff {
+			return p.MaxBackoff
+		}
+	}
+	return wait
+}
+
+// how long to wait before sending the request again, false if it should not be resent
+func (c *Client) retryDelay(method string, attempt int, err error) (time.Duration, bool) {
+	if c.retry == nil || method != http.MethodGet || attempt+1 >= c.retry.MaxAttempts {
+		return 0, false
+	}
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return 0, false
+	}
+	wait := c.retry.backoff(attempt)
+	apiErr, ok := AsAPIError(err)
+	if !ok {
+		// transport error
+		return wait, true
+	}
+	switch apiErr.Class() {
+	case ErrClassRetryable:
+		return wait, true
+	case ErrClassRateLimited:
+		if apiErr.RetryAfter > c.retry.MaxBackoff {
+			return 0, false
+		}
+		if apiErr.RetryAfter > wait {
+			wait = apiErr.RetryAfter
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func (c *Client) sleep(wait time.Duration) error {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-c.Context().Done():
+		return c.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// the request may have reached the matching engine
+func isAmbiguousOrderErr(err error) bool {
+	if errors.Is(err, ErrRateLimited) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	apiErr, ok := AsAPIError(err)
+	if !ok {
+		// lost on the way back, or a deadline hit after sending
+		return true
+	}
+	return apiErr.Class() == ErrClassUnknownExecution
+}
+
+// send the order at most once, clientID is the idempotency key.
+// After an ambiguous failure the order is queried by clientID. It is only resent when the exchange still doesn't know it
+// after the recvWindow of the last send is over, because the late request would be rejected by then.
+// A duplicated client order id is only rejected while the first order is open, so resending any earlier could place it twice.
+func (c *Client) placeOrderOnce(product, path string, opts interface{}, clientID string, query func() ([]byte, error)) ([]byte, error) {
+	sentAt := time.Now()
+	res, err := c.do(product, http.MethodPost, path, opts, true, false)
+	if err == nil || c.retry == nil || clientID == "" || !isAmbiguousOrderErr(err) {
+		return res, err
+	}
+	lastErr := err
+	for attempt := 0; attempt+1 < c.retry.MaxAttempts; attempt++ {
+		if errSleep := c.sleep(c.retry.backoff(attempt)); errSleep != nil {
+			break
+		}
+		queriedAt := time.Now()
+		res, err := query()
+		if err == nil {
+			// the first request got through
+			return res, nil
+		}
+		if !IsErrorCode(err, ErrCodeNoSuchOrder) {
+			lastErr = err
+			continue
+		}
+		if wait := c.recvWindowOver(sentAt).Sub(queriedAt); wait > 0 {
+			// the last request may still be on the way, look again once the exchange has to reject it
+			lastErr = err
+			if errSleep := c.sleep(wait); errSleep != nil {
+				break
+			}
+			continue
+		}
+		sentAt = time.Now()
+		res, err = c.do(product, http.MethodPost, path, opts, true, false)
+		if err == nil {
+			return res, nil
+		}
+		if !isAmbiguousOrderErr(err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("%w: client order id %s: %v", ErrOrderStatusUnknown, clientID, lastErr)
+}
+
+// a request sent at sentAt is rejected by the exchange after this, one more second for the clock offset
+func (c *Client) recvWindowOver(sentAt time.Time) time.Time {
+	return sentAt.Add(time.Duration(c.window)*time.Millisecond + time.Second)
+}
@@ -0,0 +1,256 @@
+package bnnapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// the new order is the embedded SpotOrderRequest, the one to cancel is CancelOrderID or CancelOrigClientOrderID
+type SpotCancelReplaceRequest struct {
+	SpotOrderRequest
+	// STOP_ON_FAILURE or ALLOW_FAILURE, STOP_ON_FAILURE by default
+	CancelReplaceMode       string `url:"cancelReplaceMode"`
+	CancelOrderID           int64  `url:"cancelOrderId,omitempty"`
+	CancelOrigClientOrderID string `url:"cancelOrigClientOrderId,omitempty"`
+	CancelNewClientOrderID  string `url:"cancelNewClientOrderId,omitempty"`
+	// ONLY_NEW or ONLY_PARTIALLY_FILLED
+	CancelRestrictions string `url:"cancelRestrictions,omitempty"`
+	// DO_NOTHING or CANCEL_ONLY, what to do when the unfilled order count is exceeded
+	OrderRateLimitExceededMode string `url:"orderRateLimitExceededMode,omitempty"`
+}
+
+// CancelResult and NewOrderResult are SUCCESS, FAILURE or NOT_ATTEMPTED.
+// The failed leg has its error in CancelError or NewOrderError instead of a response.
+// When the new order was only found by its client order id after an ambiguous failure,
+// CancelResult is empty and NewOrderResponse is the queried order.
+type SpotCancelReplaceResult struct {
+	CancelResult     string
+	NewOrderResult   string
+	CancelResponse   *SpotCancelOrderResponse
+	CancelError      *APIError
+	NewOrderResponse *SpotOrderResponse
+	NewOrderError    *APIError
+}
+
+type SpotAmendOrderOpts struct {
+	Symbol            string `url:"symbol"`
+	OrderID           int64  `url:"orderId,omitempty"`
+	OrigClientOrderID string `url:"origClientOrderId,omitempty"`
+	NewClientOrderID  string `url:"newClientOrderId,omitempty"`
+	NewQty            string `url:"newQty"`
+}
+
+type SpotAmendOrderResponse struct {
+	TransactTime int64 `json:"transactTime"`
+	ExecutionID  int64 `json:"executionId"`
+	AmendedOrder struct {
+		Symbol                  string `json:"symbol"`
+		OrderID                 int64  `json:"orderId"`
+		OrderListID             int64  `json:"orderListId"`
+		OrigClientOrderID       string `json:"origClientOrderId"`
+		ClientOrderID           string `json:"clientOrderId"`
+		Price                   string `json:"price"`
+		Qty                     string `json:"qty"`
+		ExecutedQty             string `json:"executedQty"`
+		PreventedQty            string `json:"preventedQty"`
+		QuoteOrderQty           string `json:"quoteOrderQty"`
+		CumulativeQuoteQty      string `json:"cumulativeQuoteQty"`
+		Status                  string `json:"status"`
+		TimeInForce             string `json:"timeInForce"`
+		Type                    string `json:"type"`
+		Side                    string `json:"side"`
+		WorkingTime             int64  `json:"workingTime"`
+		SelfTradePreventionMode string `json:"selfTradePreventionMode"`
+	} `json:"amendedOrder"`
+	// only when the order is part of an order list
+	ListStatus *SpotOrderListResponse `json:"listStatus,omitempty"`
+}
+
+// cancel an order and place a new one in a single request.
+// When one of the legs fails both the result and the *APIError are returned, the result tells which leg failed.
+// It is never resent, the cancel leg may have run already. After an ambiguous failure the new order is
+// queried by its client order id, ErrOrderStatusUnknown is returned if it is not found so the caller can reconcile.
+func (b *Client) SpotCancelReplace(req SpotCancelReplaceRequest) (*SpotCancelReplaceResult, error) {
+	req.normalize()
+	req.CancelReplaceMode = strings.ToUpper(req.CancelReplaceMode)
+	req.CancelRestrictions = strings.ToUpper(req.CancelRestrictions)
+	req.OrderRateLimitExceededMode = strings.ToUpper(req.OrderRateLimitExceededMode)
+	if req.CancelReplaceMode == "" {
+		req.CancelReplaceMode = "STOP_ON_FAILURE"
+	}
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := b.checkOrder("spot", req.Symbol, req.checkFields()); err != nil {
+		return nil, err
+	}
+	if b.dryRun.enabled {
+		return nil, ErrDryRunUnsupported
+	}
+	if req.ClientID == "" {
+		// always set, it is the only way to find the new order after an ambiguous failure
+		req.ClientID = NewClientOrderID()
+	}
+	res, err := b.do("spot", http.MethodPost, "api/v3/order/cancelReplace", req, true, false)
+	if err != nil && isAmbiguousOrderErr(err) {
+		return b.findCancelReplaceOrder(req.Symbol, req.ClientID, err)
+	}
+	if err != nil {
+		apiErr, ok := AsAPIError(err)
+		if !ok || (apiErr.Code != ErrCodeCancelReplacePartial && apiErr.Code != ErrCodeCancelReplaceFailed) {
+			return nil, err
+		}
+		// the legs are in "data" next to code and msg
+		var body struct {
+			Data cancelReplaceData `json:"data"`
+		}
+		if errJson := json.Unmarshal(apiErr.Body, &body); errJson != nil {
+			return nil, err
+		}
+		return body.Data.result(apiErr.StatusCode), err
+	}
+	var data cancelReplaceData
+	err = json.Unmarshal(res, &data)
+	if err != nil {
+		return nil, err
+	}
+	return data.result(http.StatusOK), nil
+}
+
+// check the fields which are not checked by SpotOrderRequest.Validate
+func (r *SpotCancelReplaceRequest) Validate() error {
+	if err := r.SpotOrderRequest.Validate(); err != nil {
+		return err
+	}
+	switch r.CancelReplaceMode {
+	case "STOP_ON_FAILURE", "ALLOW_FAILURE":
+	default:
+		return invalidOrder("unknown cancelReplaceMode %q", r.CancelReplaceMode)
+	}
+	if r.CancelOrderID == 0 && r.CancelOrigClientOrderID == "" {
+		return invalidOrder("cancelOrderId or cancelOrigClientOrderId is needed")
+	}
+	switch r.CancelRestrictions {
+	case "", "ONLY_NEW", "ONLY_PARTIALLY_FILLED":
+	default:
+		return invalidOrder("unknown cancelRestrictions %q", r.CancelRestrictions)
+	}
+	switch r.OrderRateLimitExceededMode {
+	case "", "DO_NOTHING", "CANCEL_ONLY":
+	default:
+		return invalidOrder("unknown orderRateLimitExceededMode %q", r.OrderRateLimitExceededMode)
+	}
+	return nil
+}
+
+// reduce the qty of an open order without losing its place in the queue, set OrderID or OrigClientOrderID.
+// NewClientOrderID renames the amended order.
+func (b *Client) SpotAmendKeepPriority(opts SpotAmendOrderOpts) (*SpotAmendOrderResponse, error) {
+	opts.Symbol = strings.ToUpper(opts.Symbol)
+	if opts.Symbol == "" {
+		return nil, invalidOrder("symbol is missing")
+	}
+	if opts.OrderID == 0 && opts.OrigClientOrderID == "" {
+		return nil, invalidOrder("orderId or origClientOrderId is needed")
+	}
+	if opts.NewQty == "" {
+		return nil, invalidOrder("newQty is missing")
+	}
+	if b.dryRun.enabled {
+		return nil, ErrDryRunUnsupported
+	}
+	res, err := b.do("spot", http.MethodPut, "api/v3/order/amend/keepPriority", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := &SpotAmendOrderResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// internal funcs ------------------------------------------------
+
+// look for the new order of a cancelReplace which failed ambiguously, with the retry policy if there is one
+func (b *Client) findCancelReplaceOrder(symbol, clientID string, sendErr error) (*SpotCancelReplaceResult, error) {
+	query := b.spotQueryByClientID(symbol, clientID)
+	attempts := 1
+	if b.retry != nil && b.retry.MaxAttempts > 1 {
+		attempts = b.retry.MaxAttempts - 1
+	}
+	lastErr := sendErr
+	for attempt := 0; attempt < attempts; attempt++ {
+		if b.retry != nil {
+			if errSleep := b.sleep(b.retry.backoff(attempt)); errSleep != nil {
+				break
+			}
+		}
+		res, err := query()
+		if err == nil {
+			resp := &SpotOrderResponse{}
+			err = json.Unmarshal(res, resp)
+			if err != nil {
+				return nil, err
+			}
+			return &SpotCancelReplaceResult{
+				NewOrderResult:   "SUCCESS",
+				NewOrderResponse: resp,
+			}, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("%w: client order id %s: %v", ErrOrderStatusUnknown, clientID, lastErr)
+}
+
+// a failed leg is {"code", "msg"} instead of the order
+type cancelReplaceData struct {
+	CancelResult     string                 `json:"cancelResult"`
+	NewOrderResult   string                 `json:"newOrderResult"`
+	CancelResponse   map[string]interface{} `json:"cancelResponse"`
+	NewOrderResponse map[string]interface{} `json:"newOrderResponse"`
+}
+
+func (d *cancelReplaceData) result(status int) *SpotCancelReplaceResult {
+	result := &SpotCancelReplaceResult{
+		CancelResult:   d.CancelResult,
+		NewOrderResult: d.NewOrderResult,
+	}
+	if d.CancelResponse != nil {
+		if d.CancelResult == "FAILURE" {
+			result.CancelError = legError(d.CancelResponse, status)
+		} else {
+			result.CancelResponse = &SpotCancelOrderResponse{}
+			remarshal(d.CancelResponse, result.CancelResponse)
+		}
+	}
+	if d.NewOrderResponse != nil {
+		if d.NewOrderResult == "FAILURE" {
+			result.NewOrderError = legError(d.NewOrderResponse, status)
+		} else {
+			result.NewOrderResponse = &SpotOrderResponse{}
+			remarshal(d.NewOrderResponse, result.NewOrderResponse)
+		}
+	}
+	return result
+}
+
+func legError(leg map[string]interface{}, status int) *APIError {
+	apiErr := &APIError{
+		StatusCode: status,
+		Method:     http.MethodPost,
+		Path:       "api/v3/order/cancelReplace",
+	}
+	remarshal(leg, apiErr)
+	return apiErr
+}
+
+func remarshal(from map[string]interface{}, to interface{}) {
+	b, err := json.Marshal(from)
+	if err != nil {
+		return
+	}
+	_ = json.Unmarshal(b, to)
+}
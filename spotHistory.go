@@ -0,0 +1,323 @@
+package bnnapi
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+type SpotAllOrdersOpts struct {
+	Symbol string `url:"symbol"`
+	// orders from this id on
+	OrderID   int64 `url:"orderId,omitempty"`
+	StartTime int64 `url:"startTime,omitempty"`
+	EndTime   int64 `url:"endTime,omitempty"`
+	// max 1000, 500 by default
+	Limit int `url:"limit,omitempty"`
+}
+
+type SpotMyTradesOpts struct {
+	Symbol    string `url:"symbol"`
+	OrderID   int64  `url:"orderId,omitempty"`
+	StartTime int64  `url:"startTime,omitempty"`
+	EndTime   int64  `url:"endTime,omitempty"`
+	// trades from this id on
+	FromID int64 `url:"fromId,omitempty"`
+	// max 1000, 500 by default
+	Limit int `url:"limit,omitempty"`
+}
+
+type SpotMyTradesResponse struct {
+	Symbol          string `json:"symbol"`
+	ID              int64  `json:"id"`
+	OrderID         int64  `json:"orderId"`
+	OrderListID     int64  `json:"orderListId"`
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	QuoteQty        string `json:"quoteQty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+	Time            int64  `json:"time"`
+	IsBuyer         bool   `json:"isBuyer"`
+	IsMaker         bool   `json:"isMaker"`
+	IsBestMatch     bool   `json:"isBestMatch"`
+}
+
+type SpotPreventedMatchesOpts struct {
+	Symbol               string `url:"symbol"`
+	PreventedMatchID     int64  `url:"preventedMatchId,omitempty"`
+	OrderID              int64  `url:"orderId,omitempty"`
+	FromPreventedMatchID int64  `url:"fromPreventedMatchId,omitempty"`
+	// max 1000, 500 by default
+	Limit int `url:"limit,omitempty"`
+}
+
+type SpotPreventedMatchResponse struct {
+	Symbol                  string `json:"symbol"`
+	PreventedMatchID        int64  `json:"preventedMatchId"`
+	TakerOrderID            int64  `json:"takerOrderId"`
+	MakerSymbol             string `json:"makerSymbol"`
+	MakerOrderID            int64  `json:"makerOrderId"`
+	TradeGroupID            int64  `json:"tradeGroupId"`
+	SelfTradePreventionMode string `json:"selfTradePreventionMode"`
+	Price                   string `json:"price"`
+	MakerPreventedQuantity  string `json:"makerPreventedQuantity"`
+	TransactTime            int64  `json:"transactTime"`
+}
+
+// every order of the symbol, the time window can't be longer than 24h
+func (b *Client) SpotAllOrders(opts SpotAllOrdersOpts) ([]SpotQueryOrderResponse, error) {
+	opts.Symbol = strings.ToUpper(opts.Symbol)
+	res, err := b.do("spot", http.MethodGet, "api/v3/allOrders", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var resp []SpotQueryOrderResponse
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// the trades of the account, the time window can't be longer than 24h
+func (b *Client) SpotMyTrades(opts SpotMyTradesOpts) ([]SpotMyTradesResponse, error) {
+	opts.Symbol = strings.ToUpper(opts.Symbol)
+	res, err := b.do("spot", http.MethodGet, "api/v3/myTrades", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var resp []SpotMyTradesResponse
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// the matches which were expired by self trade prevention, set PreventedMatchID or OrderID
+func (b *Client) SpotPreventedMatches(opts SpotPreventedMatchesOpts) ([]SpotPreventedMatchResponse, error) {
+	opts.Symbol = strings.ToUpper(opts.Symbol)
+	res, err := b.do("spot", http.MethodGet, "api/v3/myPreventedMatches", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var resp []SpotPreventedMatchResponse
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// SpotOrderIterator walks the order history page by page:
+//
+//	it := client.SpotOrderHistory("BTCUSDT", 0, start, end)
+//	for it.Next() {
+//		order := it.Order()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type SpotOrderIterator struct {
+	client *Client
+	symbol string
+	cursor *historyCursor
+	buf    []SpotQueryOrderResponse
+	cur    SpotQueryOrderResponse
+	err    error
+}
+
+// walk by order id from fromOrderID when it's set, otherwise by 24h windows from start to end.
+// Zero end is now, start is needed without fromOrderID.
+func (b *Client) SpotOrderHistory(symbol string, fromOrderID int64, start, end time.Time) *SpotOrderIterator {
+	it := &SpotOrderIterator{
+		client: b,
+		symbol: strings.ToUpper(symbol),
+	}
+	if fromOrderID != 0 {
+		it.cursor = newHistoryCursorByID(fromOrderID, start, end, 1000)
+	} else {
+		it.cursor = newHistoryCursor(start, end, spotHistoryWindow, 1000)
+		if start.IsZero() {
+			it.err = errHistoryStart
+		}
+	}
+	return it
+}
+
+func (it *SpotOrderIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.err != nil {
+			return false
+		}
+		page, ok := it.cursor.page()
+		if !ok {
+			return false
+		}
+		orders, err := it.client.SpotAllOrders(SpotAllOrdersOpts{
+			Symbol:    it.symbol,
+			OrderID:   page.FromID,
+			StartTime: page.StartTime,
+			EndTime:   page.EndTime,
+			Limit:     it.cursor.limit,
+		})
+		if err != nil {
+			it.err = err
+			return false
+		}
+		keys := make([]historyKey, len(orders))
+		for i, order := range orders {
+			keys[i] = historyKey{ID: int64(order.OrderID), Time: order.Time}
+		}
+		for i, keep := range it.cursor.advance(keys) {
+			if keep {
+				it.buf = append(it.buf, orders[i])
+			}
+		}
+	}
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+func (it *SpotOrderIterator) Order() SpotQueryOrderResponse {
+	return it.cur
+}
+
+// the error which stopped the walk, nil when it's done
+func (it *SpotOrderIterator) Err() error {
+	return it.err
+}
+
+// SpotTradeIterator walks the trade history page by page, used the same way as SpotOrderIterator
+type SpotTradeIterator struct {
+	client *Client
+	symbol string
+	cursor *historyCursor
+	buf    []SpotMyTradesResponse
+	cur    SpotMyTradesResponse
+	err    error
+}
+
+// walk by trade id from fromID when it's set, otherwise by 24h windows from start to end.
+// Zero end is now, start is needed without fromID.
+func (b *Client) SpotTradeHistory(symbol string, fromID int64, start, end time.Time) *SpotTradeIterator {
+	it := &SpotTradeIterator{
+		client: b,
+		symbol: strings.ToUpper(symbol),
+	}
+	if fromID != 0 {
+		it.cursor = newHistoryCursorByID(fromID, start, end, 1000)
+	} else {
+		it.cursor = newHistoryCursor(start, end, spotHistoryWindow, 1000)
+		if start.IsZero() {
+			it.err = errHistoryStart
+		}
+	}
+	return it
+}
+
+func (it *SpotTradeIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.err != nil {
+			return false
+		}
+		page, ok := it.cursor.page()
+		if !ok {
+			return false
+		}
+		trades, err := it.client.SpotMyTrades(SpotMyTradesOpts{
+			Symbol:    it.symbol,
+			FromID:    page.FromID,
+			StartTime: page.StartTime,
+			EndTime:   page.EndTime,
+			Limit:     it.cursor.limit,
+		})
+		if err != nil {
+			it.err = err
+			return false
+		}
+		keys := make([]historyKey, len(trades))
+		for i, trade := range trades {
+			keys[i] = historyKey{ID: trade.ID, Time: trade.Time}
+		}
+		for i, keep := range it.cursor.advance(keys) {
+			if keep {
+				it.buf = append(it.buf, trades[i])
+			}
+		}
+	}
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+func (it *SpotTradeIterator) Trade() SpotMyTradesResponse {
+	return it.cur
+}
+
+// the error which stopped the walk, nil when it's done
+func (it *SpotTradeIterator) Err() error {
+	return it.err
+}
+
+// SpotPreventedMatchIterator walks the prevented matches of an order, used the same way as SpotOrderIterator
+type SpotPreventedMatchIterator struct {
+	client  *Client
+	symbol  string
+	orderID int64
+	cursor  *historyCursor
+	buf     []SpotPreventedMatchResponse
+	cur     SpotPreventedMatchResponse
+	err     error
+}
+
+// the exchange pages the prevented matches by id within one order only
+func (b *Client) SpotPreventedMatchHistory(symbol string, orderID, fromPreventedMatchID int64) *SpotPreventedMatchIterator {
+	return &SpotPreventedMatchIterator{
+		client:  b,
+		symbol:  strings.ToUpper(symbol),
+		orderID: orderID,
+		cursor:  newHistoryCursorByID(fromPreventedMatchID, time.Time{}, time.Time{}, 1000),
+	}
+}
+
+func (it *SpotPreventedMatchIterator) Next() bool {
+	for len(it.buf) == 0 {
+		if it.err != nil {
+			return false
+		}
+		page, ok := it.cursor.page()
+		if !ok {
+			return false
+		}
+		matches, err := it.client.SpotPreventedMatches(SpotPreventedMatchesOpts{
+			Symbol:               it.symbol,
+			OrderID:              it.orderID,
+			FromPreventedMatchID: page.FromID,
+			Limit:                it.cursor.limit,
+		})
+		if err != nil {
+			it.err = err
+			return false
+		}
+		keys := make([]historyKey, len(matches))
+		for i, match := range matches {
+			keys[i] = historyKey{ID: match.PreventedMatchID, Time: match.TransactTime}
+		}
+		for i, keep := range it.cursor.advance(keys) {
+			if keep {
+				it.buf = append(it.buf, matches[i])
+			}
+		}
+	}
+	it.cur, it.buf = it.buf[0], it.buf[1:]
+	return true
+}
+
+func (it *SpotPreventedMatchIterator) PreventedMatch() SpotPreventedMatchResponse {
+	return it.cur
+}
+
+// the error which stopped the walk, nil when it's done
+func (it *SpotPreventedMatchIterator) Err() error {
+	return it.err
+}
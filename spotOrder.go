@@ -0,0 +1,178 @@
+package bnnapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidOrder = errors.New("invalid order")
+
+// SpotOrderRequest covers every parameter of api/v3/order, empty fields are not sent
+type SpotOrderRequest struct {
+	Symbol      string `url:"symbol"`
+	Side        string `url:"side"`
+	Type        string `url:"type"`
+	TimeInForce string `url:"timeInForce,omitempty"`
+	Price       string `url:"price,omitempty"`
+	Qty         string `url:"quantity,omitempty"`
+	// market orders only, spend or receive this much of the quote asset
+	QuoteOrderQty string `url:"quoteOrderQty,omitempty"`
+	StopPrice     string `url:"stopPrice,omitempty"`
+	// in BIPS, 100 is 1%
+	TrailingDelta int    `url:"trailingDelta,omitempty"`
+	IcebergQty    string `url:"icebergQty,omitempty"`
+	ClientID      string `url:"newClientOrderId,omitempty"`
+	// ACK, RESULT or FULL
+	NewOrderRespType string `url:"newOrderRespType,omitempty"`
+	// EXPIRE_TAKER, EXPIRE_MAKER, EXPIRE_BOTH, DECREMENT or NONE
+	SelfTradePreventionMode string `url:"selfTradePreventionMode,omitempty"`
+	StrategyID              int64  `url:"strategyId,omitempty"`
+	// should be at least 1000000
+	StrategyType int64 `url:"strategyType,omitempty"`
+}
+
+// place any kind of spot order, the request is checked on our side first.
+// TimeInForce defaults to GTC for the limit types.
+func (b *Client) SpotPlaceOrderRequest(req SpotOrderRequest) (*SpotOrderResponse, error) {
+	req.normalize()
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	if err := b.checkOrder("spot", req.Symbol, req.checkFields()); err != nil {
+		return nil, err
+	}
+	if b.dryRun.enabled {
+		return b.dryRunSpotOrder(req)
+	}
+	req.ClientID = b.orderClientID(req.ClientID)
+	res, err := b.placeOrderOnce("spot", "api/v3/order", req, req.ClientID, b.spotQueryByClientID(req.Symbol, req.ClientID))
+	if err != nil {
+		return nil, err
+	}
+	resp := &SpotOrderResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// check the combination of the parameters for the order type
+func (r *SpotOrderRequest) Validate() error {
+	if r.Symbol == "" {
+		return invalidOrder("symbol is missing")
+	}
+	if r.Side != "BUY" && r.Side != "SELL" {
+		return invalidOrder("unknown side %q", r.Side)
+	}
+	var needPrice, needTrigger, needTIF bool
+	switch r.Type {
+	case "LIMIT":
+		needPrice, needTIF = true, true
+	case "MARKET":
+	case "STOP_LOSS", "TAKE_PROFIT":
+		needTrigger = true
+	case "STOP_LOSS_LIMIT", "TAKE_PROFIT_LIMIT":
+		needPrice, needTrigger, needTIF = true, true, true
+	case "LIMIT_MAKER":
+		needPrice = true
+	default:
+		return invalidOrder("unknown order type %q", r.Type)
+	}
+	if r.Type == "MARKET" {
+		if (r.Qty == "") == (r.QuoteOrderQty == "") {
+			return invalidOrder("MARKET needs either quantity or quoteOrderQty")
+		}
+	} else {
+		if r.Qty == "" {
+			return invalidOrder("%s needs quantity", r.Type)
+		}
+		if r.QuoteOrderQty != "" {
+			return invalidOrder("quoteOrderQty is only for MARKET orders")
+		}
+	}
+	if needPrice && r.Price == "" {
+		return invalidOrder("%s needs price", r.Type)
+	}
+	if !needPrice && r.Price != "" {
+		return invalidOrder("%s doesn't take price", r.Type)
+	}
+	hasTrigger := r.StopPrice != "" || r.TrailingDelta != 0
+	if needTrigger && !hasTrigger {
+		return invalidOrder("%s needs stopPrice or trailingDelta", r.Type)
+	}
+	if !needTrigger && hasTrigger {
+		return invalidOrder("%s doesn't take stopPrice or trailingDelta", r.Type)
+	}
+	if r.TrailingDelta < 0 {
+		return invalidOrder("trailingDelta should be positive")
+	}
+	switch r.TimeInForce {
+	case "":
+		if needTIF {
+			return invalidOrder("%s needs timeInForce", r.Type)
+		}
+	case "GTC", "IOC", "FOK":
+		if !needTIF {
+			return invalidOrder("%s doesn't take timeInForce", r.Type)
+		}
+	default:
+		return invalidOrder("unknown timeInForce %q", r.TimeInForce)
+	}
+	if r.IcebergQty != "" {
+		if !needPrice {
+			return invalidOrder("icebergQty is only for limit orders")
+		}
+		if r.TimeInForce != "" && r.TimeInForce != "GTC" {
+			return invalidOrder("icebergQty needs timeInForce GTC")
+		}
+	}
+	switch r.NewOrderRespType {
+	case "", "ACK", "RESULT", "FULL":
+	default:
+		return invalidOrder("unknown newOrderRespType %q", r.NewOrderRespType)
+	}
+	switch r.SelfTradePreventionMode {
+	case "", "EXPIRE_TAKER", "EXPIRE_MAKER", "EXPIRE_BOTH", "DECREMENT", "NONE":
+	default:
+		return invalidOrder("unknown selfTradePreventionMode %q", r.SelfTradePreventionMode)
+	}
+	if r.StrategyType != 0 && r.StrategyType < 1000000 {
+		return invalidOrder("strategyType should be at least 1000000")
+	}
+	return nil
+}
+
+// internal funcs ------------------------------------------------
+
+func (r *SpotOrderRequest) checkFields() orderFields {
+	return orderFields{
+		side:          r.Side,
+		orderType:     r.Type,
+		price:         &r.Price,
+		qty:           &r.Qty,
+		stopPrice:     &r.StopPrice,
+		icebergQty:    &r.IcebergQty,
+		quoteOrderQty: &r.QuoteOrderQty,
+	}
+}
+
+func (r *SpotOrderRequest) normalize() {
+	r.Symbol = strings.ToUpper(r.Symbol)
+	r.Side = strings.ToUpper(r.Side)
+	r.Type = strings.ToUpper(r.Type)
+	r.TimeInForce = strings.ToUpper(r.TimeInForce)
+	r.NewOrderRespType = strings.ToUpper(r.NewOrderRespType)
+	r.SelfTradePreventionMode = strings.ToUpper(r.SelfTradePreventionMode)
+	if r.TimeInForce == "" {
+		switch r.Type {
+		case "LIMIT", "STOP_LOSS_LIMIT", "TAKE_PROFIT_LIMIT":
+			r.TimeInForce = "GTC"
+		}
+	}
+}
+
+func invalidOrder(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidOrder, fmt.Sprintf(format, args...))
+}
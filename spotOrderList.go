@@ -0,0 +1,396 @@
+package bnnapi
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// both legs of api/v3/orderList/oco, the above leg is the one with the higher price
+type SpotOCORequest struct {
+	Symbol            string `url:"symbol"`
+	ListClientOrderID string `url:"listClientOrderId,omitempty"`
+	Side              string `url:"side"`
+	Qty               string `url:"quantity"`
+	// STOP_LOSS_LIMIT, STOP_LOSS, LIMIT_MAKER, TAKE_PROFIT or TAKE_PROFIT_LIMIT
+	AboveType          string `url:"aboveType"`
+	AboveClientOrderID string `url:"aboveClientOrderId,omitempty"`
+	AboveIcebergQty    string `url:"aboveIcebergQty,omitempty"`
+	AbovePrice         string `url:"abovePrice,omitempty"`
+	AboveStopPrice     string `url:"aboveStopPrice,omitempty"`
+	AboveTrailingDelta int    `url:"aboveTrailingDelta,omitempty"`
+	AboveTimeInForce   string `url:"aboveTimeInForce,omitempty"`
+	AboveStrategyID    int64  `url:"aboveStrategyId,omitempty"`
+	AboveStrategyType  int64  `url:"aboveStrategyType,omitempty"`
+	// STOP_LOSS, STOP_LOSS_LIMIT, TAKE_PROFIT or TAKE_PROFIT_LIMIT
+	BelowType               string `url:"belowType"`
+	BelowClientOrderID      string `url:"belowClientOrderId,omitempty"`
+	BelowIcebergQty         string `url:"belowIcebergQty,omitempty"`
+	BelowPrice              string `url:"belowPrice,omitempty"`
+	BelowStopPrice          string `url:"belowStopPrice,omitempty"`
+	BelowTrailingDelta      int    `url:"belowTrailingDelta,omitempty"`
+	BelowTimeInForce        string `url:"belowTimeInForce,omitempty"`
+	BelowStrategyID         int64  `url:"belowStrategyId,omitempty"`
+	BelowStrategyType       int64  `url:"belowStrategyType,omitempty"`
+	NewOrderRespType        string `url:"newOrderRespType,omitempty"`
+	SelfTradePreventionMode string `url:"selfTradePreventionMode,omitempty"`
+}
+
+// the pending order is placed once the working order is fully filled
+type SpotOTORequest struct {
+	Symbol                  string `url:"symbol"`
+	ListClientOrderID       string `url:"listClientOrderId,omitempty"`
+	NewOrderRespType        string `url:"newOrderRespType,omitempty"`
+	SelfTradePreventionMode string `url:"selfTradePreventionMode,omitempty"`
+	// LIMIT or LIMIT_MAKER
+	WorkingType          string `url:"workingType"`
+	WorkingSide          string `url:"workingSide"`
+	WorkingClientOrderID string `url:"workingClientOrderId,omitempty"`
+	WorkingPrice         string `url:"workingPrice"`
+	WorkingQty           string `url:"workingQuantity"`
+	WorkingIcebergQty    string `url:"workingIcebergQty,omitempty"`
+	WorkingTimeInForce   string `url:"workingTimeInForce,omitempty"`
+	WorkingStrategyID    int64  `url:"workingStrategyId,omitempty"`
+	WorkingStrategyType  int64  `url:"workingStrategyType,omitempty"`
+	PendingType          string `url:"pendingType"`
+	PendingSide          string `url:"pendingSide"`
+	PendingClientOrderID string `url:"pendingClientOrderId,omitempty"`
+	PendingPrice         string `url:"pendingPrice,omitempty"`
+	PendingStopPrice     string `url:"pendingStopPrice,omitempty"`
+	PendingTrailingDelta int    `url:"pendingTrailingDelta,omitempty"`
+	PendingQty           string `url:"pendingQuantity"`
+	PendingIcebergQty    string `url:"pendingIcebergQty,omitempty"`
+	PendingTimeInForce   string `url:"pendingTimeInForce,omitempty"`
+	PendingStrategyID    int64  `url:"pendingStrategyId,omitempty"`
+	PendingStrategyType  int64  `url:"pendingStrategyType,omitempty"`
+}
+
+// the pending pair is an OCO placed once the working order is fully filled
+type SpotOTOCORequest struct {
+	Symbol                  string `url:"symbol"`
+	ListClientOrderID       string `url:"listClientOrderId,omitempty"`
+	NewOrderRespType        string `url:"newOrderRespType,omitempty"`
+	SelfTradePreventionMode string `url:"selfTradePreventionMode,omitempty"`
+	// LIMIT or LIMIT_MAKER
+	WorkingType               string `url:"workingType"`
+	WorkingSide               string `url:"workingSide"`
+	WorkingClientOrderID      string `url:"workingClientOrderId,omitempty"`
+	WorkingPrice              string `url:"workingPrice"`
+	WorkingQty                string `url:"workingQuantity"`
+	WorkingIcebergQty         string `url:"workingIcebergQty,omitempty"`
+	WorkingTimeInForce        string `url:"workingTimeInForce,omitempty"`
+	WorkingStrategyID         int64  `url:"workingStrategyId,omitempty"`
+	WorkingStrategyType       int64  `url:"workingStrategyType,omitempty"`
+	PendingSide               string `url:"pendingSide"`
+	PendingQty                string `url:"pendingQuantity"`
+	PendingAboveType          string `url:"pendingAboveType"`
+	PendingAboveClientOrderID string `url:"pendingAboveClientOrderId,omitempty"`
+	PendingAbovePrice         string `url:"pendingAbovePrice,omitempty"`
+	PendingAboveStopPrice     string `url:"pendingAboveStopPrice,omitempty"`
+	PendingAboveTrailingDelta int    `url:"pendingAboveTrailingDelta,omitempty"`
+	PendingAboveIcebergQty    string `url:"pendingAboveIcebergQty,omitempty"`
+	PendingAboveTimeInForce   string `url:"pendingAboveTimeInForce,omitempty"`
+	PendingAboveStrategyID    int64  `url:"pendingAboveStrategyId,omitempty"`
+	PendingAboveStrategyType  int64  `url:"pendingAboveStrategyType,omitempty"`
+	PendingBelowType          string `url:"pendingBelowType,omitempty"`
+	PendingBelowClientOrderID string `url:"pendingBelowClientOrderId,omitempty"`
+	PendingBelowPrice         string `url:"pendingBelowPrice,omitempty"`
+	PendingBelowStopPrice     string `url:"pendingBelowStopPrice,omitempty"`
+	PendingBelowTrailingDelta int    `url:"pendingBelowTrailingDelta,omitempty"`
+	PendingBelowIcebergQty    string `url:"pendingBelowIcebergQty,omitempty"`
+	PendingBelowTimeInForce   string `url:"pendingBelowTimeInForce,omitempty"`
+	PendingBelowStrategyID    int64  `url:"pendingBelowStrategyId,omitempty"`
+	PendingBelowStrategyType  int64  `url:"pendingBelowStrategyType,omitempty"`
+}
+
+type SpotOrderListResponse struct {
+	OrderListID       int64  `json:"orderListId"`
+	ContingencyType   string `json:"contingencyType"`
+	ListStatusType    string `json:"listStatusType"`
+	ListOrderStatus   string `json:"listOrderStatus"`
+	ListClientOrderID string `json:"listClientOrderId"`
+	TransactionTime   int64  `json:"transactionTime"`
+	Symbol            string `json:"symbol"`
+	Orders            []struct {
+		Symbol        string `json:"symbol"`
+		OrderID       int64  `json:"orderId"`
+		ClientOrderID string `json:"clientOrderId"`
+	} `json:"orders"`
+	// only in the responses of placing and canceling
+	OrderReports []SpotOrderResponse `json:"orderReports"`
+}
+
+type SpotOrderListOpts struct {
+	Symbol            string `url:"symbol,omitempty"`
+	OrderListID       int64  `url:"orderListId,omitempty"`
+	ListClientOrderID string `url:"listClientOrderId,omitempty"`
+}
+
+type SpotQueryOrderListOpts struct {
+	OrderListID       int64  `url:"orderListId,omitempty"`
+	OrigClientOrderID string `url:"origClientOrderId,omitempty"`
+}
+
+type SpotAllOrderListsOpts struct {
+	FromID    int64 `url:"fromId,omitempty"`
+	StartTime int64 `url:"startTime,omitempty"`
+	EndTime   int64 `url:"endTime,omitempty"`
+	Limit     int   `url:"limit,omitempty"`
+}
+
+// OCO with the new api/v3/orderList/oco endpoint
+func (b *Client) SpotPlaceOCO(req SpotOCORequest) (*SpotOrderListResponse, error) {
+	req.Symbol = strings.ToUpper(req.Symbol)
+	req.Side = strings.ToUpper(req.Side)
+	req.AboveType = strings.ToUpper(req.AboveType)
+	req.BelowType = strings.ToUpper(req.BelowType)
+	if req.Symbol == "" || req.Qty == "" {
+		return nil, invalidOrder("OCO needs symbol and quantity")
+	}
+	if req.Side != "BUY" && req.Side != "SELL" {
+		return nil, invalidOrder("unknown side %q", req.Side)
+	}
+	if err := checkListLeg("above", req.AboveType, req.AbovePrice, req.AboveStopPrice, req.AboveTrailingDelta, true); err != nil {
+		return nil, err
+	}
+	if err := checkListLeg("below", req.BelowType, req.BelowPrice, req.BelowStopPrice, req.BelowTrailingDelta, false); err != nil {
+		return nil, err
+	}
+	req.AboveTimeInForce = legTimeInForce(req.AboveType, req.AboveTimeInForce)
+	req.BelowTimeInForce = legTimeInForce(req.BelowType, req.BelowTimeInForce)
+	req.ListClientOrderID = b.orderClientID(req.ListClientOrderID)
+	return b.placeOrderList("api/v3/orderList/oco", req, req.ListClientOrderID)
+}
+
+func (b *Client) SpotPlaceOTO(req SpotOTORequest) (*SpotOrderListResponse, error) {
+	req.Symbol = strings.ToUpper(req.Symbol)
+	req.WorkingType = strings.ToUpper(req.WorkingType)
+	req.WorkingSide = strings.ToUpper(req.WorkingSide)
+	req.PendingType = strings.ToUpper(req.PendingType)
+	req.PendingSide = strings.ToUpper(req.PendingSide)
+	if err := checkWorkingLeg(req.Symbol, req.WorkingType, req.WorkingSide, req.WorkingPrice, req.WorkingQty); err != nil {
+		return nil, err
+	}
+	if req.PendingSide != "BUY" && req.PendingSide != "SELL" {
+		return nil, invalidOrder("unknown pending side %q", req.PendingSide)
+	}
+	if req.PendingQty == "" {
+		return nil, invalidOrder("pending order needs quantity")
+	}
+	if err := checkPendingLeg("pending", req.PendingType, req.PendingPrice, req.PendingStopPrice, req.PendingTrailingDelta); err != nil {
+		return nil, err
+	}
+	req.WorkingTimeInForce = legTimeInForce(req.WorkingType, req.WorkingTimeInForce)
+	req.PendingTimeInForce = legTimeInForce(req.PendingType, req.PendingTimeInForce)
+	req.ListClientOrderID = b.orderClientID(req.ListClientOrderID)
+	return b.placeOrderList("api/v3/orderList/oto", req, req.ListClientOrderID)
+}
+
+func (b *Client) SpotPlaceOTOCO(req SpotOTOCORequest) (*SpotOrderListResponse, error) {
+	req.Symbol = strings.ToUpper(req.Symbol)
+	req.WorkingType = strings.ToUpper(req.WorkingType)
+	req.WorkingSide = strings.ToUpper(req.WorkingSide)
+	req.PendingSide = strings.ToUpper(req.PendingSide)
+	req.PendingAboveType = strings.ToUpper(req.PendingAboveType)
+	req.PendingBelowType = strings.ToUpper(req.PendingBelowType)
+	if err := checkWorkingLeg(req.Symbol, req.WorkingType, req.WorkingSide, req.WorkingPrice, req.WorkingQty); err != nil {
+		return nil, err
+	}
+	if req.PendingSide != "BUY" && req.PendingSide != "SELL" {
+		return nil, invalidOrder("unknown pending side %q", req.PendingSide)
+	}
+	if req.PendingQty == "" {
+		return nil, invalidOrder("pending orders need quantity")
+	}
+	if err := checkListLeg("pending above", req.PendingAboveType, req.PendingAbovePrice, req.PendingAboveStopPrice, req.PendingAboveTrailingDelta, true); err != nil {
+		return nil, err
+	}
+	// the below leg is optional
+	if req.PendingBelowType != "" {
+		if err := checkListLeg("pending below", req.PendingBelowType, req.PendingBelowPrice, req.PendingBelowStopPrice, req.PendingBelowTrailingDelta, false); err != nil {
+			return nil, err
+		}
+	}
+	req.WorkingTimeInForce = legTimeInForce(req.WorkingType, req.WorkingTimeInForce)
+	req.PendingAboveTimeInForce = legTimeInForce(req.PendingAboveType, req.PendingAboveTimeInForce)
+	req.PendingBelowTimeInForce = legTimeInForce(req.PendingBelowType, req.PendingBelowTimeInForce)
+	req.ListClientOrderID = b.orderClientID(req.ListClientOrderID)
+	return b.placeOrderList("api/v3/orderList/otoco", req, req.ListClientOrderID)
+}
+
+// cancel the whole list by orderListID, or by listClientOrderID when orderListID is 0
+func (b *Client) SpotCancelOrderList(symbol string, orderListID int64, listClientOrderID string) (*SpotOrderListResponse, error) {
+	opts := SpotOrderListOpts{
+		Symbol:            strings.ToUpper(symbol),
+		OrderListID:       orderListID,
+		ListClientOrderID: listClientOrderID,
+	}
+	if orderListID == 0 && listClientOrderID == "" {
+		return nil, invalidOrder("orderListId or listClientOrderId is needed")
+	}
+	res, err := b.do("spot", http.MethodDelete, "api/v3/orderList", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	resp := &SpotOrderListResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// query by orderListID, or by listClientOrderID when orderListID is 0
+func (b *Client) SpotQueryOrderList(orderListID int64, listClientOrderID string) (*SpotOrderListResponse, error) {
+	if orderListID == 0 && listClientOrderID == "" {
+		return nil, invalidOrder("orderListId or listClientOrderId is needed")
+	}
+	res, err := b.spotQueryOrderList(orderListID, listClientOrderID)()
+	if err != nil {
+		return nil, err
+	}
+	resp := &SpotOrderListResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (b *Client) SpotOpenOrderLists() ([]SpotOrderListResponse, error) {
+	res, err := b.do("spot", http.MethodGet, "api/v3/openOrderList", nil, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var resp []SpotOrderListResponse
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// order lists of [start, end] or from fromID on, limit is 500 by default and 1000 at most
+func (b *Client) SpotAllOrderLists(fromID int64, start, end time.Time, limit int) ([]SpotOrderListResponse, error) {
+	opts := SpotAllOrderListsOpts{
+		FromID: fromID,
+		Limit:  limit,
+	}
+	if !start.IsZero() {
+		opts.StartTime = start.UnixMilli()
+	}
+	if !end.IsZero() {
+		opts.EndTime = end.UnixMilli()
+	}
+	res, err := b.do("spot", http.MethodGet, "api/v3/allOrderList", opts, true, false)
+	if err != nil {
+		return nil, err
+	}
+	var resp []SpotOrderListResponse
+	err = json.Unmarshal(res, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// internal funcs ------------------------------------------------
+
+func (b *Client) placeOrderList(path string, opts interface{}, listClientOrderID string) (*SpotOrderListResponse, error) {
+	if b.dryRun.enabled {
+		return nil, ErrDryRunUnsupported
+	}
+	res, err := b.placeOrderOnce("spot", path, opts, listClientOrderID, b.spotQueryOrderList(0, listClientOrderID))
+	if err != nil {
+		return nil, err
+	}
+	resp := &SpotOrderListResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (b *Client) spotQueryOrderList(orderListID int64, listClientOrderID string) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		opts := SpotQueryOrderListOpts{
+			OrderListID: orderListID,
+		}
+		if orderListID == 0 {
+			opts.OrigClientOrderID = listClientOrderID
+		}
+		return b.do("spot", http.MethodGet, "api/v3/orderList", opts, true, false)
+	}
+}
+
+func checkWorkingLeg(symbol, orderType, side, price, qty string) error {
+	if symbol == "" {
+		return invalidOrder("symbol is missing")
+	}
+	if orderType != "LIMIT" && orderType != "LIMIT_MAKER" {
+		return invalidOrder("working order should be LIMIT or LIMIT_MAKER, not %q", orderType)
+	}
+	if side != "BUY" && side != "SELL" {
+		return invalidOrder("unknown working side %q", side)
+	}
+	if price == "" || qty == "" {
+		return invalidOrder("working order needs price and quantity")
+	}
+	return nil
+}
+
+// the above leg can be LIMIT_MAKER, the below leg can't
+func checkListLeg(leg, orderType, price, stopPrice string, trailingDelta int, above bool) error {
+	if orderType == "LIMIT_MAKER" {
+		if !above {
+			return invalidOrder("%s leg can't be LIMIT_MAKER", leg)
+		}
+		if price == "" {
+			return invalidOrder("%s LIMIT_MAKER needs price", leg)
+		}
+		return nil
+	}
+	if orderType == "LIMIT" || orderType == "MARKET" {
+		return invalidOrder("%s leg can't be %s", leg, orderType)
+	}
+	return checkPendingLeg(leg, orderType, price, stopPrice, trailingDelta)
+}
+
+// GTC for the leg types which need a time in force
+func legTimeInForce(orderType, timeInForce string) string {
+	if timeInForce != "" {
+		return strings.ToUpper(timeInForce)
+	}
+	switch orderType {
+	case "LIMIT", "STOP_LOSS_LIMIT", "TAKE_PROFIT_LIMIT":
+		return "GTC"
+	}
+	return ""
+}
+
+func checkPendingLeg(leg, orderType, price, stopPrice string, trailingDelta int) error {
+	switch orderType {
+	case "LIMIT", "LIMIT_MAKER", "MARKET":
+		if stopPrice != "" || trailingDelta != 0 {
+			return invalidOrder("%s %s doesn't take stopPrice or trailingDelta", leg, orderType)
+		}
+		if orderType != "MARKET" && price == "" {
+			return invalidOrder("%s %s needs price", leg, orderType)
+		}
+	case "STOP_LOSS", "TAKE_PROFIT":
+		if stopPrice == "" && trailingDelta == 0 {
+			return invalidOrder("%s %s needs stopPrice or trailingDelta", leg, orderType)
+		}
+	case "STOP_LOSS_LIMIT", "TAKE_PROFIT_LIMIT":
+		if stopPrice == "" && trailingDelta == 0 {
+			return invalidOrder("%s %s needs stopPrice or trailingDelta", leg, orderType)
+		}
+		if price == "" {
+			return invalidOrder("%s %s needs price", leg, orderType)
+		}
+	default:
+		return invalidOrder("unknown %s order type %q", leg, orderType)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package bnnapi
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// executionReport event of the spot user data stream, one for every change of an order
+type SpotOrderUpdate struct {
+	Symbol        string
+	OrderID       int64
+	ClientOrderID string
+	// the client id of the canceled order, empty for the other execution types
+	OrigClientOrderID string
+	// -1 when the order is not in an order list
+	OrderListID   int64
+	Side          string
+	Type          string
+	TimeInForce   string
+	Price         decimal.Decimal
+	Qty           decimal.Decimal
+	StopPrice     decimal.Decimal
+	IcebergQty    decimal.Decimal
+	QuoteOrderQty decimal.Decimal
+	// NEW, CANCELED, REPLACED, REJECTED, TRADE, EXPIRED or TRADE_PREVENTION
+	ExecutionType string
+	// NEW, PARTIALLY_FILLED, FILLED, CANCELED, REJECTED, EXPIRED or EXPIRED_IN_MATCH
+	Status string
+	// NONE unless rejected
+	RejectReason       string
+	CumulativeQty      decimal.Decimal
+	CumulativeQuoteQty decimal.Decimal
+	// the last fill, zero unless ExecutionType is TRADE
+	LastQty                 decimal.Decimal
+	LastPrice               decimal.Decimal
+	LastQuoteQty            decimal.Decimal
+	Commission              decimal.Decimal
+	CommissionAsset         string
+	TradeID                 int64
+	IsMaker                 bool
+	IsWorking               bool
+	SelfTradePreventionMode string
+	EventTime               time.Time
+	TransactionTime         time.Time
+	CreationTime            time.Time
+}
+
+// balanceUpdate event of the spot user data stream, for deposits, withdrawals and transfers
+type SpotBalanceUpdate struct {
+	Asset     string
+	Delta     decimal.Decimal
+	ClearTime time.Time
+	EventTime time.Time
+}
+
+type orderUpdatesBranch struct {
+	sync.Mutex
+	subs   []chan SpotOrderUpdate
+	closed bool
+}
+
+type balanceUpdatesBranch struct {
+	sync.Mutex
+	data []SpotBalanceUpdate
+}
+
+// every executionReport in the order of the stream, the channel is closed by CloseSpotUserData.
+// The stream never waits for a subscriber, one whose buffer is full is closed and dropped,
+// subscribe again and resync the open orders from the rest api then.
+func (c *Client) SubscribeSpotOrderUpdates(buffer int) <-chan SpotOrderUpdate {
+	return c.spotUser.orderUpdates.subscribe(buffer)
+}
+
+// every balanceUpdate event since the last read
+func (c *Client) ReadSpotBalanceUpdates() []SpotBalanceUpdate {
+	c.spotUser.balanceUpdates.Lock()
+	defer c.spotUser.balanceUpdates.Unlock()
+	updates := c.spotUser.balanceUpdates.data
+	c.spotUser.balanceUpdates.data = []SpotBalanceUpdate{}
+	return updates
+}
+
+// internal funcs ------------------------------------------------
+
+func (o *orderUpdatesBranch) subscribe(buffer int) <-chan SpotOrderUpdate {
+	ch := make(chan SpotOrderUpdate, buffer)
+	o.Lock()
+	defer o.Unlock()
+	if o.closed {
+		close(ch)
+		return ch
+	}
+	o.subs = append(o.subs, ch)
+	return ch
+}
+
+// a subscriber which is full has missed the update, close it so it knows
+func (o *orderUpdatesBranch) publish(update SpotOrderUpdate) {
+	o.Lock()
+	defer o.Unlock()
+	subs := o.subs[:0]
+	for _, ch := range o.subs {
+		select {
+		case ch <- update:
+			subs = append(subs, ch)
+		default:
+			close(ch)
+		}
+	}
+	o.subs = subs
+}
+
+func (o *orderUpdatesBranch) close() {
+	o.Lock()
+	defer o.Unlock()
+	for _, ch := range o.subs {
+		close(ch)
+	}
+	o.subs = nil
+	o.closed = true
+}
+
+func parseSpotOrderUpdate(res *map[string]interface{}) (SpotOrderUpdate, bool) {
+	m := *res
+	update := SpotOrderUpdate{}
+	var ok bool
+	if update.Symbol, ok = m["s"].(string); !ok {
+		return update, false
+	}
+	if oid, ok := m["i"].(float64); ok {
+		update.OrderID = int64(oid)
+	} else {
+		return update, false
+	}
+	update.ClientOrderID, _ = m["c"].(string)
+	update.OrigClientOrderID, _ = m["C"].(string)
+	update.OrderListID = int64(mapFloat(m, "g"))
+	update.Side, _ = m["S"].(string)
+	update.Type, _ = m["o"].(string)
+	update.TimeInForce, _ = m["f"].(string)
+	update.Price = mapDecimal(m, "p")
+	update.Qty = mapDecimal(m, "q")
+	update.StopPrice = mapDecimal(m, "P")
+	update.IcebergQty = mapDecimal(m, "F")
+	update.QuoteOrderQty = mapDecimal(m, "Q")
+	update.ExecutionType, _ = m["x"].(string)
+	update.Status, _ = m["X"].(string)
+	update.RejectReason, _ = m["r"].(string)
+	update.CumulativeQty = mapDecimal(m, "z")
+	update.CumulativeQuoteQty = mapDecimal(m, "Z")
+	update.LastQty = mapDecimal(m, "l")
+	update.LastPrice = mapDecimal(m, "L")
+	update.LastQuoteQty = mapDecimal(m, "Y")
+	update.Commission = mapDecimal(m, "n")
+	update.CommissionAsset, _ = m["N"].(string)
+	update.TradeID = int64(mapFloat(m, "t"))
+	update.IsMaker, _ = m["m"].(bool)
+	update.IsWorking, _ = m["w"].(bool)
+	update.SelfTradePreventionMode, _ = m["V"].(string)
+	update.EventTime = mapTime(m, "E")
+	update.TransactionTime = mapTime(m, "T")
+	update.CreationTime = mapTime(m, "O")
+	return update, true
+}
+
+func (u *spotUserDataBranch) handleBalanceUpdate(res *map[string]interface{}) {
+	m := *res
+	asset, ok := m["a"].(string)
+	if !ok {
+		return
+	}
+	update := SpotBalanceUpdate{
+		Asset:     asset,
+		Delta:     mapDecimal(m, "d"),
+		ClearTime: mapTime(m, "T"),
+		EventTime: mapTime(m, "E"),
+	}
+	u.balanceUpdates.Lock()
+	defer u.balanceUpdates.Unlock()
+	u.balanceUpdates.data = append(u.balanceUpdates.data, update)
+}
+
+// zero when missing or not a number
+func mapDecimal(m map[string]interface{}, key string) decimal.Decimal {
+	if value, ok := m[key].(string); ok {
+		d, _ := decimal.NewFromString(value)
+		return d
+	}
+	return decimal.Zero
+}
+
+func mapFloat(m map[string]interface{}, key string) float64 {
+	value, _ := m[key].(float64)
+	return value
+}
+
+// zero time when missing, kept to the millisecond
+func mapTime(m map[string]interface{}, key string) time.Time {
+	if value, ok := m[key].(float64); ok && value != 0 {
+		return time.UnixMilli(int64(value))
+	}
+	return time.Time{}
+}
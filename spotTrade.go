@@ -6,57 +6,24 @@ import (
 )
 
 func (b *Client) SpotPlaceOrder(symbol, side string, price, size string, orderType, timeInforce string) (*SpotOrderResponse, error) {
-	usymbol := strings.ToUpper(symbol)
-	uside := strings.ToUpper(side)
-	utype := strings.ToUpper(orderType)
-	opts := PlaceOrderOpts{
-		Symbol: usymbol,
-		Side:   uside,
-		Price:  price,
-		Qty:    size,
-		Type:   utype,
-	}
-	if timeInforce == "" {
-		switch orderType {
-		case "LIMIT_MAKER":
-			//
-		default:
-			opts.TimeInForce = "GTC"
-		}
-	} else {
-		opts.TimeInForce = strings.ToUpper(timeInforce)
-	}
-	res, err := b.do("spot", http.MethodPost, "api/v3/order", opts, true, false)
-	if err != nil {
-		return nil, err
-	}
-	resp := &SpotOrderResponse{}
-	err = json.Unmarshal(res, resp)
-	if err != nil {
-		return nil, err
-	}
-	return resp, nil
+	return b.SpotPlaceOrderRequest(SpotOrderRequest{
+		Symbol:      symbol,
+		Side:        side,
+		Type:        orderType,
+		Price:       price,
+		Qty:         size,
+		TimeInForce: timeInforce,
+	})
 }
 
 func (b *Client) SpotPlaceOrderMarket(symbol, side string, size string, clientID string) (*SpotOrderResponse, error) {
-	usymbol := strings.ToUpper(symbol)
-	uside := strings.ToUpper(side)
-	opts := PlaceOrderOptsMarket{
-		Symbol: usymbol,
-		Side:   uside,
-		Qty:    size,
-		Type:   "MARKET",
-	}
-	res, err := b.do("spot", http.MethodPost, "api/v3/order", opts, true, false)
-	if err != nil {
-		return nil, err
-	}
-	resp := &SpotOrderResponse{}
-	err = json.Unmarshal(res, resp)
-	if err != nil {
-		return nil, err
-	}
-	return resp, nil
+	return b.SpotPlaceOrderRequest(SpotOrderRequest{
+		Symbol:   symbol,
+		Side:     side,
+		Type:     "MARKET",
+		Qty:      size,
+		ClientID: clientID,
+	})
 }
 
 type PlaceOrderOpts struct {
@@ -66,8 +33,10 @@ type PlaceOrderOpts struct {
 	TimeInForce string `url:"timeInForce,omitempty"`
 	Type        string `url:"type"`
 	Side        string `url:"side"`
+	ClientID    string `url:"newClientOrderId,omitempty"`
 }
 
+// Deprecated: SpotPlaceOrderMarket sends a SpotOrderRequest, use SpotPlaceOrderRequest with it instead.
 type PlaceOrderOptsMarket struct {
 	Symbol   string `url:"symbol"`
 	Qty      string `url:"quantity"`
@@ -90,12 +59,22 @@ type SpotOrderResponse struct {
 	TimeInForce         string `json:"timeInForce"`
 	Type                string `json:"type"`
 	Side                string `json:"side"`
+	StopPrice           string `json:"stopPrice"`
+	IcebergQty          string `json:"icebergQty"`
+	TrailingDelta       int    `json:"trailingDelta"`
+	StrategyID          int64  `json:"strategyId"`
+	StrategyType        int64  `json:"strategyType"`
+	WorkingTime         int64  `json:"workingTime"`
+	SelfTradePrevention string `json:"selfTradePreventionMode"`
 	Fills               []struct {
 		Price           string `json:"price"`
 		Qty             string `json:"qty"`
 		Commission      string `json:"commission"`
 		CommissionAsset string `json:"commissionAsset"`
+		TradeID         int64  `json:"tradeId"`
 	} `json:"fills, omitempty"`
+	// commission rates of the test order in dry run, nil otherwise
+	DryRun *SpotTestOrderResponse `json:"-"`
 }
 
 func (b *Client) SpotCancelOrder(symbol string, oid int) (*SpotCancelOrderResponse, error) {
@@ -162,6 +141,36 @@ type SpotQueryOrderResponse struct {
 	UpdateTime          int64  `json:"updateTime"`
 	IsWorking           bool   `json:"isWorking"`
 	OrigQuoteOrderQty   string `json:"origQuoteOrderQty"`
+	WorkingTime         int64  `json:"workingTime"`
+	SelfTradePrevention string `json:"selfTradePreventionMode"`
+}
+
+type SpotClientOIDOpts struct {
+	Symbol   string `url:"symbol"`
+	ClientID string `url:"origClientOrderId"`
+}
+
+func (b *Client) SpotQueryOrderByClientID(symbol, clientID string) (*SpotQueryOrderResponse, error) {
+	res, err := b.spotQueryByClientID(strings.ToUpper(symbol), clientID)()
+	if err != nil {
+		return nil, err
+	}
+	resp := &SpotQueryOrderResponse{}
+	err = json.Unmarshal(res, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (b *Client) spotQueryByClientID(symbol, clientID string) func() ([]byte, error) {
+	return func() ([]byte, error) {
+		opts := SpotClientOIDOpts{
+			Symbol:   symbol,
+			ClientID: clientID,
+		}
+		return b.do("spot", http.MethodGet, "api/v3/order", opts, true, false)
+	}
 }
 
 func (b *Client) SpotQueryOrder(symbol string, oid int) (*SpotQueryOrderResponse, error) {
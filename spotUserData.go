@@ -21,6 +21,22 @@ type spotUserDataBranch struct {
 	httpUpdateInterval int
 	errs               chan error
 	trades             userTradesBranch
+	orderLists         orderListsBranch
+	link               streamLinkBranch
+	orderUpdates       orderUpdatesBranch
+	balanceUpdates     balanceUpdatesBranch
+}
+
+// last sign of life from the stream, a message or a ping
+type streamLinkBranch struct {
+	sync.RWMutex
+	lastSeen time.Time
+}
+
+type orderListsBranch struct {
+	sync.RWMutex
+	open    map[int64]OrderListUpdate
+	updates []OrderListUpdate
 }
 
 type userTradesBranch struct {
@@ -44,6 +60,27 @@ type TradeData struct {
 	Fee       decimal.Decimal
 	FeeAsset  string
 	TimeStamp time.Time
+	// perp only, BOTH in one-way mode and LONG or SHORT in hedge mode
+	PositionSide string
+}
+
+// listStatus event of the spot user data stream
+type OrderListUpdate struct {
+	Symbol            string
+	OrderListID       int64
+	ContingencyType   string
+	ListStatusType    string
+	ListOrderStatus   string
+	RejectReason      string
+	ListClientOrderID string
+	TimeStamp         time.Time
+	Orders            []OrderListLeg
+}
+
+type OrderListLeg struct {
+	Symbol        string
+	OrderID       int64
+	ClientOrderID string
 }
 
 type SpotAccountSnapShotResponse struct {
@@ -70,6 +107,7 @@ type SpotAccountSnapShotOpts struct {
 
 func (u *Client) CloseSpotUserData() {
 	(*u.spotUser.cancel)()
+	u.spotUser.orderUpdates.close()
 }
 
 // default is 60 sec
@@ -91,6 +129,26 @@ func (c *Client) ReadSpotUserTrade() []TradeData {
 	return trades
 }
 
+// every listStatus event since the last read
+func (c *Client) ReadSpotOrderListUpdates() []OrderListUpdate {
+	c.spotUser.orderLists.Lock()
+	defer c.spotUser.orderLists.Unlock()
+	updates := c.spotUser.orderLists.updates
+	c.spotUser.orderLists.updates = []OrderListUpdate{}
+	return updates
+}
+
+// the last state of the order lists which are not ALL_DONE yet
+func (c *Client) GetSpotOpenOrderLists() []OrderListUpdate {
+	c.spotUser.orderLists.RLock()
+	defer c.spotUser.orderLists.RUnlock()
+	lists := make([]OrderListUpdate, 0, len(c.spotUser.orderLists.open))
+	for _, list := range c.spotUser.orderLists.open {
+		lists = append(lists, list)
+	}
+	return lists
+}
+
 func (c *Client) InitSpotPrivateChannel(logger *log.Logger) {
 	c.spotLocalUserData(logger)
 }
@@ -183,7 +241,11 @@ func (c *Client) spotLocalUserData(logger *log.Logger) {
 	ctx, cancel := context.WithCancel(context.Background())
 	u.cancel = &cancel
 	u.httpUpdateInterval = 60
+	u.orderLists.open = make(map[int64]OrderListUpdate)
+	u.link.alive()
 	u.initialChannels()
+	// the stream reports to the branch before the first connection is done
+	c.spotUser = &u
 	userData := make(chan map[string]interface{}, 100)
 	// stream user data
 	go func() {
@@ -192,7 +254,7 @@ func (c *Client) spotLocalUserData(logger *log.Logger) {
 			case <-ctx.Done():
 				return
 			default:
-				res, err := c.GetListenKeyHub("spot", "") // delete listen key
+				res, err := c.WithContext(ctx).GetListenKeyHub("spot", "") // delete listen key
 				if err != nil {
 					logger.Println("retry listen key for user data stream in 5 sec..")
 					time.Sleep(time.Second * 5)
@@ -220,7 +282,6 @@ func (c *Client) spotLocalUserData(logger *log.Logger) {
 		}
 	}()
 	// wait for connecting
-	c.spotUser = &u
 	time.Sleep(time.Second * 5)
 }
 
@@ -240,6 +301,7 @@ func (u *spotUserDataBranch) maintainUserData(
 	client *Client,
 	userData *chan map[string]interface{},
 ) error {
+	client = client.WithContext(ctx)
 	// get the first snapshot to initial data struct
 	if err := u.getAccountSnapShot(client); err != nil {
 		return err
@@ -272,20 +334,19 @@ func (u *spotUserDataBranch) maintainUserData(
 			}
 			switch event {
 			case "outboundAccountPosition":
-				if data, ok := message["a"].(map[string]interface{}); !ok {
-					continue
-				} else {
-					u.updateAccountData(&data)
-				}
+				// the balances are in "B" of the event itself
+				u.updateAccountData(&message)
 			case "executionReport":
-				if event, ok := message["x"].(string); ok {
-					switch event {
-					case "TRADE":
-						u.handleTrade(&message)
-					default:
-						// order update in the future
-					}
+				if update, ok := parseSpotOrderUpdate(&message); ok {
+					u.orderUpdates.publish(update)
+				}
+				if event, ok := message["x"].(string); ok && event == "TRADE" {
+					u.handleTrade(&message)
 				}
+			case "balanceUpdate":
+				u.handleBalanceUpdate(&message)
+			case "listStatus":
+				u.handleListStatus(&message)
 			}
 		}
 	}
@@ -298,7 +359,7 @@ func (c *Client) spotUserData(ctx context.Context, listenKey string, logger *log
 	w.OnErr = false
 	var buffer bytes.Buffer
 	innerErr := make(chan error, 1)
-	buffer.WriteString("wss://stream.binance.com:9443/ws/")
+	buffer.WriteString(c.endpoints.stream("spot"))
 	buffer.WriteString(listenKey)
 	url := buffer.String()
 	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
@@ -311,7 +372,11 @@ func (c *Client) spotUserData(ctx context.Context, listenKey string, logger *log
 	if err := w.Conn.SetReadDeadline(time.Now().Add(time.Second * duration)); err != nil {
 		return err
 	}
-	w.Conn.SetPingHandler(nil)
+	c.spotUser.link.alive()
+	w.Conn.SetPingHandler(func(appData string) error {
+		c.spotUser.link.alive()
+		return w.Conn.WriteControl(websocket.PongMessage, []byte(appData), time.Now().Add(time.Second))
+	})
 	go func() {
 		putKey := time.NewTicker(time.Minute * 30)
 		defer putKey.Stop()
@@ -322,7 +387,7 @@ func (c *Client) spotUserData(ctx context.Context, listenKey string, logger *log
 			case <-innerErr:
 				return
 			case <-putKey.C:
-				if err := c.PutListenKeyHub("spot", listenKey); err != nil {
+				if err := c.WithContext(ctx).PutListenKeyHub("spot", listenKey); err != nil {
 					// time out in 1 sec
 					w.Conn.SetReadDeadline(time.Now().Add(time.Second))
 				}
@@ -357,8 +422,9 @@ func (c *Client) spotUserData(ctx context.Context, listenKey string, logger *log
 				innerErr <- errors.New("restart")
 				return err1
 			}
+			c.spotUser.link.alive()
 			// check event time first
-			c.spotUser.handleUserData(&res, mainCh)
+			c.spotUser.handleUserData(ctx, &res, mainCh)
 			if err := w.Conn.SetReadDeadline(time.Now().Add(time.Second * duration)); err != nil {
 				innerErr <- errors.New("restart")
 				return err
@@ -367,17 +433,32 @@ func (c *Client) spotUserData(ctx context.Context, listenKey string, logger *log
 	}
 }
 
-func (u *spotUserDataBranch) handleUserData(res *map[string]interface{}, mainCh *chan map[string]interface{}) {
+func (u *spotUserDataBranch) handleUserData(ctx context.Context, res *map[string]interface{}, mainCh *chan map[string]interface{}) {
 	if eventTimeUnix, ok := (*res)["E"].(float64); ok {
 		eventTime := formatingTimeStamp(eventTimeUnix)
 		if time.Now().After(eventTime.Add(time.Minute * 60)) {
 			return
 		}
 		// insert to chan
-		*mainCh <- *res
+		select {
+		case *mainCh <- *res:
+		case <-ctx.Done():
+		}
 	}
 }
 
+func (l *streamLinkBranch) alive() {
+	l.Lock()
+	defer l.Unlock()
+	l.lastSeen = time.Now()
+}
+
+func (l *streamLinkBranch) since() time.Duration {
+	l.RLock()
+	defer l.RUnlock()
+	return time.Since(l.lastSeen)
+}
+
 func (u *spotUserDataBranch) initialChannels() {
 	// 5 err is allowed
 	u.errs = make(chan error, 5)
@@ -418,31 +499,38 @@ func (u *spotUserDataBranch) readerrs() error {
 
 // default fee asset is USDT
 func (u *spotUserDataBranch) handleTrade(res *map[string]interface{}) {
+	if data, ok := parseSpotTrade(res); ok {
+		u.insertTrade(&data)
+	}
+}
+
+// the fill of a TRADE executionReport, also used by the margin streams
+func parseSpotTrade(res *map[string]interface{}) (TradeData, bool) {
 	data := TradeData{}
 	if symbol, ok := (*res)["s"].(string); ok {
 		data.Symbol = symbol
 	} else {
-		return
+		return data, false
 	}
 	if side, ok := (*res)["S"].(string); ok {
 		data.Side = strings.ToLower(side)
 	} else {
-		return
+		return data, false
 	}
 	if qty, ok := (*res)["l"].(string); ok {
 		data.Qty, _ = decimal.NewFromString(qty)
 	} else {
-		return
+		return data, false
 	}
 	if price, ok := (*res)["L"].(string); ok {
 		data.Price, _ = decimal.NewFromString(price)
 	} else {
-		return
+		return data, false
 	}
 	if oid, ok := (*res)["i"].(float64); ok {
 		data.Oid = decimal.NewFromFloat(oid).String()
 	} else {
-		return
+		return data, false
 	}
 	if execType, ok := (*res)["m"].(bool); ok {
 		data.IsMaker = execType
@@ -460,7 +548,57 @@ func (u *spotUserDataBranch) handleTrade(res *map[string]interface{}) {
 	if feeAsset, ok := (*res)["N"].(string); ok {
 		data.FeeAsset = feeAsset
 	}
-	u.insertTrade(&data)
+	return data, true
+}
+
+func (u *spotUserDataBranch) handleListStatus(res *map[string]interface{}) {
+	data, ok := parseOrderListUpdate(res)
+	if !ok {
+		return
+	}
+	u.orderLists.Lock()
+	defer u.orderLists.Unlock()
+	if data.ListOrderStatus == "ALL_DONE" {
+		delete(u.orderLists.open, data.OrderListID)
+	} else {
+		u.orderLists.open[data.OrderListID] = data
+	}
+	u.orderLists.updates = append(u.orderLists.updates, data)
+}
+
+// also used by the margin streams
+func parseOrderListUpdate(res *map[string]interface{}) (OrderListUpdate, bool) {
+	data := OrderListUpdate{}
+	if id, ok := (*res)["g"].(float64); ok {
+		data.OrderListID = int64(id)
+	} else {
+		return data, false
+	}
+	data.Symbol, _ = (*res)["s"].(string)
+	data.ContingencyType, _ = (*res)["c"].(string)
+	data.ListStatusType, _ = (*res)["l"].(string)
+	data.ListOrderStatus, _ = (*res)["L"].(string)
+	data.RejectReason, _ = (*res)["r"].(string)
+	data.ListClientOrderID, _ = (*res)["C"].(string)
+	if st, ok := (*res)["T"].(float64); ok {
+		data.TimeStamp = formatingTimeStamp(st)
+	}
+	if orders, ok := (*res)["O"].([]interface{}); ok {
+		for _, item := range orders {
+			order, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			leg := OrderListLeg{}
+			leg.Symbol, _ = order["s"].(string)
+			leg.ClientOrderID, _ = order["c"].(string)
+			if oid, ok := order["i"].(float64); ok {
+				leg.OrderID = int64(oid)
+			}
+			data.Orders = append(data.Orders, leg)
+		}
+	}
+	return data, true
 }
 
 func (u *spotUserDataBranch) updateAccountData(message *map[string]interface{}) {
@@ -470,6 +608,9 @@ func (u *spotUserDataBranch) updateAccountData(message *map[string]interface{})
 	}
 	u.account.Lock()
 	defer u.account.Unlock()
+	if u.account.Data == nil {
+		return
+	}
 	for _, item := range array {
 		data, ok := item.(map[string]interface{})
 		if !ok {
@@ -487,12 +628,21 @@ func (u *spotUserDataBranch) updateAccountData(message *map[string]interface{})
 		if !okl {
 			continue
 		}
+		found := false
 		for idx, bal := range u.account.Data.Balances {
 			if bal.Asset == asset {
 				u.account.Data.Balances[idx].Free = free
 				u.account.Data.Balances[idx].Locked = lock
-				return
+				found = true
+				break
 			}
 		}
+		if !found {
+			u.account.Data.Balances = append(u.account.Data.Balances, SpotAccountBalances{
+				Asset:  asset,
+				Free:   free,
+				Locked: lock,
+			})
+		}
 	}
 }
@@ -24,7 +24,8 @@ type StreamMarketTradesBranch struct {
 		Trades []PublicTradeData
 		sync.Mutex
 	}
-	logger *logrus.Logger
+	logger    *logrus.Logger
+	endpoints Endpoints
 }
 
 type PublicTradeData struct {
@@ -50,14 +51,14 @@ type BnnTradeData struct {
 	M         bool   `json:"M"`
 }
 
-func PerpTradeStream(symbol string, logger *logrus.Logger) *StreamMarketTradesBranch {
+func PerpTradeStream(symbol string, logger *logrus.Logger, opts ...StreamOption) *StreamMarketTradesBranch {
 	Usymbol := strings.ToUpper(symbol)
-	return tradeStream(Usymbol, logger, "perp")
+	return tradeStream(Usymbol, logger, "perp", newStreamConfig(opts))
 }
 
-func SpotTradeStream(symbol string, logger *logrus.Logger) *StreamMarketTradesBranch {
+func SpotTradeStream(symbol string, logger *logrus.Logger, opts ...StreamOption) *StreamMarketTradesBranch {
 	Usymbol := strings.ToUpper(symbol)
-	return tradeStream(Usymbol, logger, "spot")
+	return tradeStream(Usymbol, logger, "spot", newStreamConfig(opts))
 }
 
 func (o *StreamMarketTradesBranch) GetTrades() []PublicTradeData {
@@ -75,7 +76,7 @@ func (o *StreamMarketTradesBranch) Close() {
 	o.tradesBranch.Trades = []PublicTradeData{}
 }
 
-func tradeStream(symbol string, logger *logrus.Logger, product string) *StreamMarketTradesBranch {
+func tradeStream(symbol string, logger *logrus.Logger, product string, config streamConfig) *StreamMarketTradesBranch {
 	o := new(StreamMarketTradesBranch)
 	ctx, cancel := context.WithCancel(context.Background())
 	o.cancel = &cancel
@@ -83,6 +84,7 @@ func tradeStream(symbol string, logger *logrus.Logger, product string) *StreamMa
 	o.tradeChan = make(chan PublicTradeData, 100)
 	o.logger = logger
 	o.product = product
+	o.endpoints = config.endpoints
 	go o.maintainSession(ctx, product, symbol)
 	go o.listen(ctx)
 	return o
@@ -125,12 +127,7 @@ func (o *StreamMarketTradesBranch) maintainSession(ctx context.Context, product,
 func (o *StreamMarketTradesBranch) maintain(ctx context.Context, product string, symbol string) error {
 	var duration time.Duration = 30
 	var buffer bytes.Buffer
-	switch product {
-	case "spot":
-		buffer.WriteString("wss://stream.binance.com:9443/ws/")
-	case "perp":
-		buffer.WriteString("wss://fstream.binance.com/ws/")
-	}
+	buffer.WriteString(o.endpoints.stream(product))
 	buffer.WriteString(strings.ToLower(symbol))
 	buffer.WriteString("@trade")
 	conn, _, err := websocket.DefaultDialer.Dial(buffer.String(), nil)
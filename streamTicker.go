@@ -16,11 +16,12 @@ import (
 const NullPrice = "null"
 
 type StreamTickerBranch struct {
-	bid    tobBranch
-	ask    tobBranch
-	cancel *context.CancelFunc
-	reCh   chan error
-	socket wS
+	bid       tobBranch
+	ask       tobBranch
+	cancel    *context.CancelFunc
+	reCh      chan error
+	socket    wS
+	endpoints Endpoints
 }
 
 type tobBranch struct {
@@ -30,12 +31,12 @@ type tobBranch struct {
 	timestamp time.Time
 }
 
-func PerpStreamTicker(symbol string, logger *log.Logger) *StreamTickerBranch {
-	return localStreamTicker("perp", symbol, logger)
+func PerpStreamTicker(symbol string, logger *log.Logger, opts ...StreamOption) *StreamTickerBranch {
+	return localStreamTicker("perp", symbol, logger, newStreamConfig(opts))
 }
 
-func SpotStreamTicker(symbol string, logger *log.Logger) *StreamTickerBranch {
-	return localStreamTicker("spot", symbol, logger)
+func SpotStreamTicker(symbol string, logger *log.Logger, opts ...StreamOption) *StreamTickerBranch {
+	return localStreamTicker("spot", symbol, logger, newStreamConfig(opts))
 }
 
 func (s *StreamTickerBranch) Close() {
@@ -74,8 +75,9 @@ func (s *StreamTickerBranch) GetAsk() (price, qty string, ts time.Time, ok bool)
 
 // internal
 
-func localStreamTicker(product, symbol string, logger *log.Logger) *StreamTickerBranch {
+func localStreamTicker(product, symbol string, logger *log.Logger, config streamConfig) *StreamTickerBranch {
 	var s StreamTickerBranch
+	s.endpoints = config.endpoints
 	ctx, cancel := context.WithCancel(context.Background())
 	s.cancel = &cancel
 	ticker := make(chan map[string]interface{}, 50)
@@ -186,13 +188,12 @@ func (s *StreamTickerBranch) socketTicker(
 	s.socket.Logger = logger
 	s.socket.OnErr = false
 	var buffer bytes.Buffer
+	buffer.WriteString(s.endpoints.tickerStream(product))
 	switch product {
 	case "perp":
-		buffer.WriteString("wss://fstream3.binance.com/ws/")
 		buffer.WriteString(strings.ToLower(symbol))
 		buffer.WriteString("@bookTicker")
 	default:
-		buffer.WriteString("wss://stream.binance.com:9443/ws/")
 		buffer.WriteString(strings.ToLower(symbol))
 		buffer.WriteString("@ticker")
 	}
@@ -0,0 +1,339 @@
+package bnnapi
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+	log "github.com/sirupsen/logrus"
+)
+
+// SymbolInfo is shared by every reader of the registry, don't modify it
+type SymbolInfo struct {
+	// "spot" or "future"
+	Product           string
+	Symbol            string
+	Status            string
+	BaseAsset         string
+	QuoteAsset        string
+	PricePrecision    int
+	QuantityPrecision int
+	OrderTypes        []string
+	Filters           SymbolFilters
+}
+
+type SymbolEventType int
+
+const (
+	SymbolAdded SymbolEventType = iota
+	SymbolDelisted
+	SymbolStatusChanged
+	SymbolFiltersChanged
+)
+
+func (t SymbolEventType) String() string {
+	switch t {
+	case SymbolAdded:
+		return "added"
+	case SymbolDelisted:
+		return "delisted"
+	case SymbolStatusChanged:
+		return "status changed"
+	case SymbolFiltersChanged:
+		return "filters changed"
+	}
+	return "unknown"
+}
+
+// Old is nil for SymbolAdded and New is nil for SymbolDelisted
+type SymbolEvent struct {
+	Type    SymbolEventType
+	Product string
+	Symbol  string
+	Old     *SymbolInfo
+	New     *SymbolInfo
+}
+
+type SymbolRegistryBranch struct {
+	client *Client
+	logger *log.Logger
+	cancel *context.CancelFunc
+	// one load at a time, so the events come in order
+	refreshMux sync.Mutex
+
+	mux sync.RWMutex
+	// product -> symbol -> info
+	symbols map[string]map[string]*SymbolInfo
+	// product -> asset -> infos
+	byBase  map[string]map[string][]*SymbolInfo
+	byQuote map[string]map[string][]*SymbolInfo
+	updated time.Time
+
+	subMux sync.Mutex
+	subs   []chan SymbolEvent
+}
+
+// load the spot and future exchange infos and refresh them every interval,
+// the first load is done before returning
+func NewSymbolRegistry(client *Client, interval time.Duration, logger *log.Logger) (*SymbolRegistryBranch, error) {
+	if interval <= 0 {
+		return nil, errors.New("refresh interval must be positive")
+	}
+	r := &SymbolRegistryBranch{
+		client:  client,
+		logger:  logger,
+		symbols: make(map[string]map[string]*SymbolInfo),
+		byBase:  make(map[string]map[string][]*SymbolInfo),
+		byQuote: make(map[string]map[string][]*SymbolInfo),
+	}
+	if err := r.Refresh(); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancel = &cancel
+	go r.maintain(ctx, interval)
+	return r, nil
+}
+
+func (r *SymbolRegistryBranch) Close() {
+	(*r.cancel)()
+	r.subMux.Lock()
+	defer r.subMux.Unlock()
+	for _, ch := range r.subs {
+		close(ch)
+	}
+	r.subs = nil
+}
+
+// reload both exchange infos now, events are sent for every difference to the last load
+func (r *SymbolRegistryBranch) Refresh() error {
+	return r.refresh(r.client)
+}
+
+// product is "spot" or "future"
+func (r *SymbolRegistryBranch) Symbol(product, symbol string) (*SymbolInfo, bool) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	info, ok := r.symbols[product][strings.ToUpper(symbol)]
+	return info, ok
+}
+
+func (r *SymbolRegistryBranch) Spot(symbol string) (*SymbolInfo, bool) {
+	return r.Symbol("spot", symbol)
+}
+
+func (r *SymbolRegistryBranch) Swap(symbol string) (*SymbolInfo, bool) {
+	return r.Symbol("future", symbol)
+}
+
+// every symbol of the product, sorted by name
+func (r *SymbolRegistryBranch) Symbols(product string) []*SymbolInfo {
+	r.mux.RLock()
+	infos := make([]*SymbolInfo, 0, len(r.symbols[product]))
+	for _, info := range r.symbols[product] {
+		infos = append(infos, info)
+	}
+	r.mux.RUnlock()
+	sortSymbolInfos(infos)
+	return infos
+}
+
+func (r *SymbolRegistryBranch) ByBaseAsset(product, asset string) []*SymbolInfo {
+	r.mux.RLock()
+	infos := append([]*SymbolInfo(nil), r.byBase[product][strings.ToUpper(asset)]...)
+	r.mux.RUnlock()
+	sortSymbolInfos(infos)
+	return infos
+}
+
+func (r *SymbolRegistryBranch) ByQuoteAsset(product, asset string) []*SymbolInfo {
+	r.mux.RLock()
+	infos := append([]*SymbolInfo(nil), r.byQuote[product][strings.ToUpper(asset)]...)
+	r.mux.RUnlock()
+	sortSymbolInfos(infos)
+	return infos
+}
+
+// the time of the last successful load
+func (r *SymbolRegistryBranch) LastUpdate() time.Time {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.updated
+}
+
+// events of the later refreshes, the channel is closed by Close.
+// A subscriber which doesn't keep up with the buffer misses events.
+func (r *SymbolRegistryBranch) Subscribe(buffer int) <-chan SymbolEvent {
+	ch := make(chan SymbolEvent, buffer)
+	r.subMux.Lock()
+	defer r.subMux.Unlock()
+	r.subs = append(r.subs, ch)
+	return ch
+}
+
+// use the registry for the order validation of the client instead of loading the exchange info on its own
+func (c *Client) SetSymbolRegistry(r *SymbolRegistryBranch) {
+	c.registry = r
+}
+
+// internal funcs ------------------------------------------------
+
+func (r *SymbolRegistryBranch) refresh(client *Client) error {
+	r.refreshMux.Lock()
+	defer r.refreshMux.Unlock()
+	spot, err := client.SpotInfo()
+	if err != nil {
+		return err
+	}
+	swap, err := client.SwapInfo()
+	if err != nil {
+		return err
+	}
+	symbols := map[string]map[string]*SymbolInfo{
+		"spot":   make(map[string]*SymbolInfo, len(spot.Symbols)),
+		"future": make(map[string]*SymbolInfo, len(swap.Symbols)),
+	}
+	for _, s := range spot.Symbols {
+		symbols["spot"][s.Symbol] = &SymbolInfo{
+			Product:    "spot",
+			Symbol:     s.Symbol,
+			Status:     s.Status,
+			BaseAsset:  s.BaseAsset,
+			QuoteAsset: s.QuoteAsset,
+			// spot has no order precision, the tick and step sizes give it
+			PricePrecision:    spotPricePrecision(s.Filters, s.QuotePrecision),
+			QuantityPrecision: spotQuantityPrecision(s.Filters, s.BaseAssetPrecision),
+			OrderTypes:        s.OrderTypes,
+			Filters:           s.Filters,
+		}
+	}
+	for _, s := range swap.Symbols {
+		symbols["future"][s.Symbol] = &SymbolInfo{
+			Product:           "future",
+			Symbol:            s.Symbol,
+			Status:            s.Status,
+			BaseAsset:         s.BaseAsset,
+			QuoteAsset:        s.QuoteAsset,
+			PricePrecision:    s.PricePrecision,
+			QuantityPrecision: s.QuantityPrecision,
+			OrderTypes:        s.OrderTypes,
+			Filters:           s.Filters,
+		}
+	}
+	byBase := make(map[string]map[string][]*SymbolInfo)
+	byQuote := make(map[string]map[string][]*SymbolInfo)
+	for product, infos := range symbols {
+		byBase[product] = make(map[string][]*SymbolInfo)
+		byQuote[product] = make(map[string][]*SymbolInfo)
+		for _, info := range infos {
+			byBase[product][info.BaseAsset] = append(byBase[product][info.BaseAsset], info)
+			byQuote[product][info.QuoteAsset] = append(byQuote[product][info.QuoteAsset], info)
+		}
+	}
+	r.mux.Lock()
+	old := r.symbols
+	r.symbols = symbols
+	r.byBase = byBase
+	r.byQuote = byQuote
+	r.updated = time.Now()
+	r.mux.Unlock()
+	// nothing to compare with on the first load
+	if len(old) != 0 {
+		r.publish(diffSymbols(old, symbols))
+	}
+	return nil
+}
+
+func (r *SymbolRegistryBranch) maintain(ctx context.Context, interval time.Duration) {
+	refresh := time.NewTicker(interval)
+	defer refresh.Stop()
+	client := r.client.WithContext(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-refresh.C:
+			if err := r.refresh(client); err != nil && !errors.Is(err, context.Canceled) {
+				// keep the last load when failing
+				r.logger.Warningf("Refreshing symbol registry with err: %s\n", err.Error())
+			}
+		}
+	}
+}
+
+func (r *SymbolRegistryBranch) publish(events []SymbolEvent) {
+	r.subMux.Lock()
+	defer r.subMux.Unlock()
+	for _, event := range events {
+		for _, ch := range r.subs {
+			select {
+			case ch <- event:
+			default:
+				r.logger.Warningf("Symbol registry subscriber is full, drop %s %s %s event\n", event.Product, event.Symbol, event.Type)
+			}
+		}
+	}
+}
+
+func diffSymbols(old, new map[string]map[string]*SymbolInfo) []SymbolEvent {
+	var events []SymbolEvent
+	for product, infos := range new {
+		for symbol, info := range infos {
+			before, ok := old[product][symbol]
+			switch {
+			case !ok:
+				events = append(events, SymbolEvent{Type: SymbolAdded, Product: product, Symbol: symbol, New: info})
+			case before.Status != info.Status:
+				events = append(events, SymbolEvent{Type: SymbolStatusChanged, Product: product, Symbol: symbol, Old: before, New: info})
+			}
+			if ok && !reflect.DeepEqual(before.Filters.Raw, info.Filters.Raw) {
+				events = append(events, SymbolEvent{Type: SymbolFiltersChanged, Product: product, Symbol: symbol, Old: before, New: info})
+			}
+		}
+	}
+	for product, infos := range old {
+		for symbol, info := range infos {
+			if _, ok := new[product][symbol]; !ok {
+				events = append(events, SymbolEvent{Type: SymbolDelisted, Product: product, Symbol: symbol, Old: info})
+			}
+		}
+	}
+	return events
+}
+
+func sortSymbolInfos(infos []*SymbolInfo) {
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Symbol < infos[j].Symbol
+	})
+}
+
+// decimals of the PRICE_FILTER tick size, the quote precision if there is no tick
+func spotPricePrecision(f SymbolFilters, fallback int) int {
+	if f.Price == nil || !f.Price.TickSize.IsPositive() {
+		return fallback
+	}
+	return stepPrecision(f.Price.TickSize)
+}
+
+// decimals of the LOT_SIZE step size, the base precision if there is no step
+func spotQuantityPrecision(f SymbolFilters, fallback int) int {
+	if f.LotSize == nil || !f.LotSize.StepSize.IsPositive() {
+		return fallback
+	}
+	return stepPrecision(f.LotSize.StepSize)
+}
+
+// e.g. 0.01000000 is 2 and 1.00000000 is 0
+func stepPrecision(step decimal.Decimal) int {
+	str := step.String()
+	idx := strings.IndexByte(str, '.')
+	if idx == -1 {
+		return 0
+	}
+	return len(str) - idx - 1
+}
@@ -2,12 +2,18 @@ package bnnapi
 
 import (
 	"net/http"
+	"strings"
 )
 
 type PutListenKeyOpts struct {
 	ListenKey string `url:"listenKey"`
 }
 
+type PutIsolatedListenKeyOpts struct {
+	Symbol    string `url:"symbol"`
+	ListenKey string `url:"listenKey"`
+}
+
 func (b *Client) GetPerpListenKey() (*ListenKeyResponse, error) {
 	res, err := b.do("future", http.MethodPost, "fapi/v1/listenKey", nil, false, true)
 	if err != nil {
@@ -107,6 +113,19 @@ func (b *Client) PutIsoMarginListenKey(listenKey string) error {
 	return nil
 }
 
+// the exchange wants the symbol as well to keep an isolated listen key alive
+func (b *Client) PutIsolatedMarginListenKey(symbol, listenKey string) error {
+	opts := PutIsolatedListenKeyOpts{
+		Symbol:    strings.ToUpper(symbol),
+		ListenKey: listenKey,
+	}
+	_, err := b.do("spot", http.MethodPut, "sapi/v1/userDataStream/isolated", opts, false, true)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 type ListenKeyResponse struct {
 	ListenKey string `json:"listenKey"`
 }